@@ -300,11 +300,21 @@ func processFile(file processor.FileInfo, cfg *config.Config, args *ProcessingAr
 		return false, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	formatted, err := formatMarkdownContent(content, cfg)
+	formatted, diagnostics, err := formatMarkdownContent(content, cfg, cfg.IsMDXFile(file.Path))
 	if err != nil {
 		return false, err
 	}
 
+	if args.check && !args.quiet {
+		for _, diagnostic := range diagnostics {
+			location := file.Path
+			if diagnostic.Pos.IsValid() {
+				location += ":" + diagnostic.Pos.String()
+			}
+			fmt.Fprintf(os.Stderr, "%s: %s\n", location, diagnostic)
+		}
+	}
+
 	changed := hasContentChanged(content, formatted)
 
 	if args.verbose && !args.quiet && changed {
@@ -319,27 +329,37 @@ func processFile(file processor.FileInfo, cfg *config.Config, args *ProcessingAr
 }
 
 // formatMarkdownContent processes markdown content through parse -> format -> render pipeline
-func formatMarkdownContent(content []byte, cfg *config.Config) (string, error) {
-	p := parser.DefaultParser()
+// and returns the diagnostics reported by the formatters
+func formatMarkdownContent(content []byte, cfg *config.Config, mdx bool) (string, []formatter.Diagnostic, error) {
+	p := newParser(cfg, mdx)
 	doc, err := p.Parse(content)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse markdown: %w", err)
+		return "", nil, fmt.Errorf("failed to parse markdown: %w", err)
 	}
 
 	engine := formatter.New()
-	engine.RegisterDefaults()
 
 	if formatErr := engine.Format(doc, cfg); formatErr != nil {
-		return "", fmt.Errorf("failed to format document: %w", formatErr)
+		return "", nil, fmt.Errorf("failed to format document: %w", formatErr)
 	}
 
 	mdRenderer := renderer.New()
 	formatted, err := mdRenderer.Render(doc, cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to render document: %w", err)
+		return "", nil, fmt.Errorf("failed to render document: %w", err)
 	}
 
-	return formatted, nil
+	return formatted, engine.Diagnostics(), nil
+}
+
+// newParser creates a parser for the Markdown dialect selected in the
+// configuration, parsing MDX if mdx is set
+func newParser(cfg *config.Config, mdx bool) parser.Parser {
+	var opts []parser.Option
+	if mdx {
+		opts = append(opts, parser.WithMDX())
+	}
+	return parser.NewGoldmarkParserFromConfig(cfg, opts...)
 }
 
 // hasContentChanged checks if the content has been modified after formatting
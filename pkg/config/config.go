@@ -17,6 +17,116 @@ const (
 	DefaultMaxBlankLines = 2
 	// ConfigFilePermissions defines the file permissions for config files
 	ConfigFilePermissions = 0o600
+
+	// HeadingStyleATX writes headings with leading hashes (## Title)
+	HeadingStyleATX = "atx"
+	// HeadingStyleATXClosed writes headings with leading and closing hashes (## Title ##)
+	HeadingStyleATXClosed = "atx-closed"
+	// HeadingStyleSetext underlines level 1 and 2 headings; deeper levels use ATX
+	HeadingStyleSetext = "setext"
+	// HeadingStylePreserve keeps each heading in the style it was written in
+	HeadingStylePreserve = "preserve"
+	// HeadingStyleConsistent writes all headings in the style of the first one
+	HeadingStyleConsistent = "consistent"
+
+	// TableStyleAligned pads table cells so that column pipes line up
+	TableStyleAligned = "aligned"
+	// TableStyleCompact writes table cells without padding
+	TableStyleCompact = "compact"
+
+	// DefaultThematicBreakStyle defines the default thematic break marker
+	DefaultThematicBreakStyle = "---"
+	// MinThematicBreakLength defines the minimum number of thematic break characters
+	MinThematicBreakLength = 3
+
+	// DefaultFrontMatterIndent defines the default indentation of re-serialized YAML front matter
+	DefaultFrontMatterIndent = 2
+	// MinFrontMatterIndent defines the minimum indentation of re-serialized YAML front matter
+	MinFrontMatterIndent = 2
+
+	// ListSpacingPreserve keeps lists tight or loose as they were written
+	ListSpacingPreserve = "preserve"
+	// ListSpacingTight writes list items without blank lines between them
+	ListSpacingTight = "tight"
+	// ListSpacingLoose separates list items with blank lines
+	ListSpacingLoose = "loose"
+
+	// OrderedNumberingIncremental numbers ordered list items up from the start number
+	OrderedNumberingIncremental = "incremental"
+	// OrderedNumberingAllOnes gives every ordered list item the start number,
+	// which is 1 unless the list starts elsewhere
+	OrderedNumberingAllOnes = "all-ones"
+	// OrderedNumberingPreserve keeps the numbers ordered list items were written with
+	OrderedNumberingPreserve = "preserve"
+
+	// HardBreakPreserve keeps hard line breaks as they were written
+	HardBreakPreserve = "preserve"
+	// HardBreakBackslash writes hard line breaks as a backslash before the line ending
+	HardBreakBackslash = "backslash"
+	// HardBreakSpaces writes hard line breaks as two trailing spaces
+	HardBreakSpaces = "spaces"
+
+	// BareURLsPreserve keeps GFM bare URLs as they were written
+	BareURLsPreserve = "preserve"
+	// BareURLsAutolink writes bare URLs as autolinks in angle brackets (<url>)
+	BareURLsAutolink = "autolink"
+	// BareURLsLink writes bare URLs as inline links labeled with the host and
+	// path ([host/path](url))
+	BareURLsLink = "link"
+
+	// TitleQuoteDouble writes link titles in double quotes ("title")
+	TitleQuoteDouble = `"`
+	// TitleQuoteSingle writes link titles in single quotes ('title')
+	TitleQuoteSingle = "'"
+	// TitleQuoteParentheses writes link titles in parentheses ((title))
+	TitleQuoteParentheses = "()"
+
+	// AdmonitionSyntaxPreserve keeps admonitions in the syntax they were written in
+	AdmonitionSyntaxPreserve = "preserve"
+	// AdmonitionSyntaxGitHub writes admonitions as GitHub alerts (> [!NOTE])
+	AdmonitionSyntaxGitHub = "github"
+	// AdmonitionSyntaxMkDocs writes admonitions as MkDocs admonitions (!!! note)
+	AdmonitionSyntaxMkDocs = "mkdocs"
+
+	// DialectCommonMark parses strict CommonMark, without syntax extensions
+	DialectCommonMark = "commonmark"
+	// DialectGFM parses GitHub Flavored Markdown: CommonMark with the
+	// extensions github.com renders
+	DialectGFM = "gfm"
+	// DialectExtended parses GFM together with the extensions of static
+	// site generators: math, MkDocs admonitions and heading attributes
+	DialectExtended = "extended"
+
+	// ExtensionTables parses GFM pipe tables
+	ExtensionTables = "tables"
+	// ExtensionStrikethrough parses GFM strikethrough (~~text~~)
+	ExtensionStrikethrough = "strikethrough"
+	// ExtensionTaskLists parses GFM task list items (- [ ] item)
+	ExtensionTaskLists = "task_lists"
+	// ExtensionAutolinks parses GFM bare URLs and www. links as autolinks
+	ExtensionAutolinks = "autolinks"
+	// ExtensionFootnotes parses footnote references and definitions ([^1])
+	ExtensionFootnotes = "footnotes"
+	// ExtensionMath parses math blocks ($$) and inline math ($x$)
+	ExtensionMath = "math"
+	// ExtensionAlerts parses GitHub alerts (> [!NOTE])
+	ExtensionAlerts = "alerts"
+	// ExtensionAdmonitions parses MkDocs admonitions (!!! note)
+	ExtensionAdmonitions = "admonitions"
+	// ExtensionHeadingAttributes parses heading attribute blocks ({#id .class})
+	ExtensionHeadingAttributes = "heading_attributes"
+	// ExtensionDefinitionLists parses PHP Markdown Extra definition lists
+	ExtensionDefinitionLists = "definition_lists"
+
+	// MDXExtension is the file extension of MDX documents, which are always parsed as MDX
+	MDXExtension = ".mdx"
+
+	// LinkReferencePlacementPreserve keeps link reference definitions where they are
+	LinkReferencePlacementPreserve = "preserve"
+	// LinkReferencePlacementDocument moves link reference definitions to the end of the document
+	LinkReferencePlacementDocument = "document"
+	// LinkReferencePlacementSection moves link reference definitions to the end of their section
+	LinkReferencePlacementSection = "section"
 )
 
 // Config represents the configuration for mdfmt
@@ -33,6 +143,30 @@ type Config struct {
 	// Code block configuration
 	Code CodeConfig `yaml:"code" json:"code"`
 
+	// Table configuration
+	Table TableConfig `yaml:"table" json:"table"`
+
+	// Thematic break configuration
+	ThematicBreak ThematicBreakConfig `yaml:"thematic_break" json:"thematic_break"`
+
+	// Front matter configuration
+	FrontMatter FrontMatterConfig `yaml:"front_matter" json:"front_matter"`
+
+	// Footnote configuration
+	Footnotes FootnoteConfig `yaml:"footnotes" json:"footnotes"`
+
+	// Link reference definition configuration
+	LinkReferences LinkReferenceConfig `yaml:"link_references" json:"link_references"`
+
+	// Inline link configuration
+	Links LinkConfig `yaml:"links" json:"links"`
+
+	// Markdown dialect configuration
+	Markdown MarkdownConfig `yaml:"markdown" json:"markdown"`
+
+	// Admonition configuration
+	Admonitions AdmonitionConfig `yaml:"admonitions" json:"admonitions"`
+
 	// Whitespace configuration
 	Whitespace WhitespaceConfig `yaml:"whitespace" json:"whitespace"`
 
@@ -42,10 +176,15 @@ type Config struct {
 
 // HeadingConfig contains heading formatting options
 type HeadingConfig struct {
-	// Style defines the heading style: "atx" (#) or "setext" (===)
+	// Style defines the heading style: "atx" (#), "atx-closed" (# #),
+	// "setext" (===), "preserve" or "consistent" (follow the first heading)
 	Style string `yaml:"style" json:"style"`
 	// NormalizeLevels fixes heading level jumps
 	NormalizeLevels bool `yaml:"normalize_levels" json:"normalize_levels"`
+	// ExplicitIDs writes the GitHub-compatible anchor of headings without an
+	// ID as an attribute block ({#id}), so that anchors survive rewording the
+	// title
+	ExplicitIDs bool `yaml:"explicit_ids" json:"explicit_ids"`
 }
 
 // ListConfig contains list formatting options
@@ -56,6 +195,12 @@ type ListConfig struct {
 	NumberStyle string `yaml:"number_style" json:"number_style"`
 	// ConsistentIndentation ensures consistent indentation
 	ConsistentIndentation bool `yaml:"consistent_indentation" json:"consistent_indentation"`
+	// Spacing defines whether list items are separated by blank lines:
+	// "preserve", "tight" or "loose"
+	Spacing string `yaml:"spacing" json:"spacing"`
+	// OrderedNumbering defines how ordered list items are numbered:
+	// "incremental", "all-ones" or "preserve"
+	OrderedNumbering string `yaml:"ordered_numbering" json:"ordered_numbering"`
 }
 
 // CodeConfig contains code block formatting options
@@ -66,6 +211,181 @@ type CodeConfig struct {
 	LanguageDetection bool `yaml:"language_detection" json:"language_detection"`
 }
 
+// TableConfig contains table formatting options
+type TableConfig struct {
+	// Style defines the table layout: "aligned" (padded columns) or "compact"
+	Style string `yaml:"style" json:"style"`
+	// LeadingPipe writes a pipe at the start of every row
+	LeadingPipe bool `yaml:"leading_pipe" json:"leading_pipe"`
+	// TrailingPipe writes a pipe at the end of every row
+	TrailingPipe bool `yaml:"trailing_pipe" json:"trailing_pipe"`
+}
+
+// ThematicBreakConfig contains thematic break formatting options
+type ThematicBreakConfig struct {
+	// Style defines the break marker: "---", "***" or "___"
+	Style string `yaml:"style" json:"style"`
+	// Length defines how many marker characters to write (0 keeps the style length)
+	Length int `yaml:"length" json:"length"`
+}
+
+// FrontMatterConfig contains front matter handling options
+type FrontMatterConfig struct {
+	// Normalize re-serializes YAML front matter instead of keeping it as written
+	Normalize bool `yaml:"normalize" json:"normalize"`
+	// SortKeys sorts mapping keys alphabetically when normalizing
+	SortKeys bool `yaml:"sort_keys" json:"sort_keys"`
+	// Indent defines the indentation width used when normalizing
+	Indent int `yaml:"indent" json:"indent"`
+}
+
+// FootnoteConfig contains footnote formatting options
+type FootnoteConfig struct {
+	// Renumber relabels footnotes 1, 2, 3... in order of first reference and
+	// reports unused definitions and undefined references
+	Renumber bool `yaml:"renumber" json:"renumber"`
+}
+
+// LinkReferenceConfig contains link reference definition options
+type LinkReferenceConfig struct {
+	// Sort orders definitions alphabetically by label
+	Sort bool `yaml:"sort" json:"sort"`
+	// Deduplicate removes definitions whose label is already defined earlier
+	Deduplicate bool `yaml:"deduplicate" json:"deduplicate"`
+	// RemoveUnused removes definitions that no link refers to
+	RemoveUnused bool `yaml:"remove_unused" json:"remove_unused"`
+	// Placement defines where definitions go: "preserve", "document" (end of
+	// the document) or "section" (end of the section that first uses them)
+	Placement string `yaml:"placement" json:"placement"`
+}
+
+// LinkConfig contains inline link and autolink formatting options
+type LinkConfig struct {
+	// BareURLs defines how GFM bare URLs are written: "preserve", "autolink"
+	// (<url>) or "link" ([host/path](url))
+	BareURLs string `yaml:"bare_urls" json:"bare_urls"`
+	// TitleQuote defines how link and image titles are delimited: "\"", "'"
+	// or "()"
+	TitleQuote string `yaml:"title_quote" json:"title_quote"`
+}
+
+// MarkdownConfig contains the Markdown syntax extensions documents are parsed with
+type MarkdownConfig struct {
+	// Dialect defines the syntax documents are parsed with: "commonmark",
+	// "gfm" or a list of extensions such as [tables, footnotes]
+	Dialect Dialect `yaml:"dialect" json:"dialect"`
+	// DefinitionLists enables PHP Markdown Extra definition lists (a term
+	// followed by ": definition")
+	DefinitionLists bool `yaml:"definition_lists" json:"definition_lists"`
+	// MDX parses every file as MDX, keeping import and export statements
+	// and JSX as written. Files with the .mdx extension always are.
+	MDX bool `yaml:"mdx" json:"mdx"`
+}
+
+// Extensions returns the syntax extensions of the dialect, with definition
+// lists added if they are enabled on their own
+func (m MarkdownConfig) Extensions() []string {
+	extensions := m.Dialect.ExtensionNames()
+	if m.DefinitionLists && !contains(extensions, ExtensionDefinitionLists) {
+		// Copied, so that the list of a preset is left as it is
+		extensions = append(append([]string{}, extensions...), ExtensionDefinitionLists)
+	}
+	return extensions
+}
+
+// HasExtension reports whether documents are parsed with the named syntax extension
+func (m MarkdownConfig) HasExtension(name string) bool {
+	return contains(m.Extensions(), name)
+}
+
+// Dialect selects the Markdown syntax documents are parsed with. In YAML it
+// is either the name of a preset dialect or a list of extension names.
+type Dialect struct {
+	// Preset is the name of a preset dialect: "commonmark", "gfm" or "extended"
+	Preset string
+	// Extensions lists the syntax extensions to parse when Preset is empty
+	Extensions []string
+}
+
+// dialectPresets lists the extensions of each preset dialect
+var dialectPresets = map[string][]string{
+	DialectCommonMark: {},
+	DialectGFM: {
+		ExtensionTables,
+		ExtensionStrikethrough,
+		ExtensionTaskLists,
+		ExtensionAutolinks,
+		ExtensionFootnotes,
+		ExtensionAlerts,
+	},
+	DialectExtended: {
+		ExtensionTables,
+		ExtensionStrikethrough,
+		ExtensionTaskLists,
+		ExtensionAutolinks,
+		ExtensionFootnotes,
+		ExtensionMath,
+		ExtensionAlerts,
+		ExtensionAdmonitions,
+		ExtensionHeadingAttributes,
+	},
+}
+
+// syntaxExtensions lists every syntax extension a dialect can name
+var syntaxExtensions = []string{
+	ExtensionTables,
+	ExtensionStrikethrough,
+	ExtensionTaskLists,
+	ExtensionAutolinks,
+	ExtensionFootnotes,
+	ExtensionMath,
+	ExtensionAlerts,
+	ExtensionAdmonitions,
+	ExtensionHeadingAttributes,
+	ExtensionDefinitionLists,
+}
+
+// ExtensionNames returns the syntax extensions of the dialect. A dialect
+// with neither a preset nor a list is GFM.
+func (d Dialect) ExtensionNames() []string {
+	if d.Preset == "" && d.Extensions == nil {
+		return dialectPresets[DialectGFM]
+	}
+	if d.Preset != "" {
+		return dialectPresets[d.Preset]
+	}
+	return d.Extensions
+}
+
+// UnmarshalYAML reads a dialect from a preset name or a list of extensions
+func (d *Dialect) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		d.Preset, d.Extensions = value.Value, nil
+		return nil
+	case yaml.SequenceNode:
+		d.Preset, d.Extensions = "", []string{}
+		return value.Decode(&d.Extensions)
+	}
+	return fmt.Errorf("line %d: dialect must be a name or a list of extensions", value.Line)
+}
+
+// MarshalYAML writes a dialect as its preset name or its list of extensions
+func (d Dialect) MarshalYAML() (interface{}, error) {
+	if d.Preset == "" && d.Extensions != nil {
+		return d.Extensions, nil
+	}
+	return d.Preset, nil
+}
+
+// AdmonitionConfig contains GitHub alert and MkDocs admonition options
+type AdmonitionConfig struct {
+	// Syntax defines how admonitions are written: "preserve", "github"
+	// (> [!NOTE]) or "mkdocs" (!!! note). Admonitions that the target
+	// syntax cannot express keep their syntax.
+	Syntax string `yaml:"syntax" json:"syntax"`
+}
+
 // WhitespaceConfig contains whitespace handling options
 type WhitespaceConfig struct {
 	// MaxBlankLines defines maximum consecutive blank lines
@@ -74,6 +394,9 @@ type WhitespaceConfig struct {
 	TrimTrailingSpaces bool `yaml:"trim_trailing_spaces" json:"trim_trailing_spaces"`
 	// EnsureFinalNewline ensures files end with a newline
 	EnsureFinalNewline bool `yaml:"ensure_final_newline" json:"ensure_final_newline"`
+	// HardBreak defines how hard line breaks are written: "preserve",
+	// "backslash" or "spaces" (two trailing spaces)
+	HardBreak string `yaml:"hard_break" json:"hard_break"`
 }
 
 // FilesConfig contains file processing options
@@ -89,25 +412,63 @@ func Default() *Config {
 	return &Config{
 		LineWidth: DefaultLineWidth,
 		Heading: HeadingConfig{
-			Style:           "atx",
+			Style:           HeadingStyleATX,
 			NormalizeLevels: true,
+			ExplicitIDs:     false,
 		},
 		List: ListConfig{
 			BulletStyle:           "-",
 			NumberStyle:           ".",
 			ConsistentIndentation: true,
+			Spacing:               ListSpacingPreserve,
+			OrderedNumbering:      OrderedNumberingIncremental,
 		},
 		Code: CodeConfig{
 			FenceStyle:        "```",
 			LanguageDetection: true,
 		},
+		Table: TableConfig{
+			Style:        TableStyleAligned,
+			LeadingPipe:  true,
+			TrailingPipe: true,
+		},
+		ThematicBreak: ThematicBreakConfig{
+			Style: DefaultThematicBreakStyle,
+		},
+		FrontMatter: FrontMatterConfig{
+			Normalize: false,
+			SortKeys:  false,
+			Indent:    DefaultFrontMatterIndent,
+		},
+		Footnotes: FootnoteConfig{
+			Renumber: false,
+		},
+		LinkReferences: LinkReferenceConfig{
+			Sort:         false,
+			Deduplicate:  false,
+			RemoveUnused: false,
+			Placement:    LinkReferencePlacementPreserve,
+		},
+		Links: LinkConfig{
+			BareURLs:   BareURLsPreserve,
+			TitleQuote: TitleQuoteDouble,
+		},
+		Markdown: MarkdownConfig{
+			Dialect:         Dialect{Preset: DialectGFM},
+			DefinitionLists: false,
+			MDX:             false,
+		},
+		Admonitions: AdmonitionConfig{
+			Syntax: AdmonitionSyntaxPreserve,
+		},
 		Whitespace: WhitespaceConfig{
 			MaxBlankLines:      DefaultMaxBlankLines,
 			TrimTrailingSpaces: true,
 			EnsureFinalNewline: true,
+			HardBreak:          HardBreakPreserve,
 		},
 		Files: FilesConfig{
-			Extensions:     []string{".md", ".markdown", ".mdown"},
+			Extensions:     []string{".md", ".markdown", ".mdown", MDXExtension},
 			IgnorePatterns: []string{"node_modules/**", ".git/**", "vendor/**"},
 		},
 	}
@@ -173,8 +534,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("line_width must be greater than 0")
 	}
 
-	if c.Heading.Style != "atx" && c.Heading.Style != "setext" {
-		return fmt.Errorf("heading.style must be 'atx' or 'setext'")
+	headingStyles := []string{
+		HeadingStyleATX,
+		HeadingStyleATXClosed,
+		HeadingStyleSetext,
+		HeadingStylePreserve,
+		HeadingStyleConsistent,
+	}
+	if !contains(headingStyles, c.Heading.Style) {
+		return fmt.Errorf("heading.style must be 'atx', 'atx-closed', 'setext', 'preserve', or 'consistent'")
 	}
 
 	if !contains([]string{"-", "*", "+"}, c.List.BulletStyle) {
@@ -185,14 +553,95 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("list.number_style must be '.' or ')'")
 	}
 
+	if !contains([]string{ListSpacingPreserve, ListSpacingTight, ListSpacingLoose}, c.List.Spacing) {
+		return fmt.Errorf("list.spacing must be 'preserve', 'tight', or 'loose'")
+	}
+
+	numberings := []string{OrderedNumberingIncremental, OrderedNumberingAllOnes, OrderedNumberingPreserve}
+	if !contains(numberings, c.List.OrderedNumbering) {
+		return fmt.Errorf("list.ordered_numbering must be 'incremental', 'all-ones', or 'preserve'")
+	}
+
 	if !contains([]string{"```", "~~~"}, c.Code.FenceStyle) {
 		return fmt.Errorf("code.fence_style must be '```' or '~~~'")
 	}
 
+	if !contains([]string{TableStyleAligned, TableStyleCompact}, c.Table.Style) {
+		return fmt.Errorf("table.style must be 'aligned' or 'compact'")
+	}
+
+	if !contains([]string{"---", "***", "___"}, c.ThematicBreak.Style) {
+		return fmt.Errorf("thematic_break.style must be '---', '***', or '___'")
+	}
+
+	if c.ThematicBreak.Length != 0 && c.ThematicBreak.Length < MinThematicBreakLength {
+		return fmt.Errorf("thematic_break.length must be 0 or >= %d", MinThematicBreakLength)
+	}
+
+	if c.FrontMatter.Normalize && c.FrontMatter.Indent < MinFrontMatterIndent {
+		return fmt.Errorf("front_matter.indent must be >= %d", MinFrontMatterIndent)
+	}
+
+	placements := []string{
+		LinkReferencePlacementPreserve,
+		LinkReferencePlacementDocument,
+		LinkReferencePlacementSection,
+	}
+	if !contains(placements, c.LinkReferences.Placement) {
+		return fmt.Errorf("link_references.placement must be 'preserve', 'document', or 'section'")
+	}
+
+	if !contains([]string{BareURLsPreserve, BareURLsAutolink, BareURLsLink}, c.Links.BareURLs) {
+		return fmt.Errorf("links.bare_urls must be 'preserve', 'autolink', or 'link'")
+	}
+
+	if !contains([]string{TitleQuoteDouble, TitleQuoteSingle, TitleQuoteParentheses}, c.Links.TitleQuote) {
+		return fmt.Errorf("links.title_quote must be '\"', \"'\", or '()'")
+	}
+
+	if err := c.Markdown.validate(); err != nil {
+		return err
+	}
+	if c.Heading.ExplicitIDs && !c.Markdown.HasExtension(ExtensionHeadingAttributes) {
+		return fmt.Errorf("heading.explicit_ids requires the '%s' extension", ExtensionHeadingAttributes)
+	}
+
+	syntaxes := []string{AdmonitionSyntaxPreserve, AdmonitionSyntaxGitHub, AdmonitionSyntaxMkDocs}
+	if !contains(syntaxes, c.Admonitions.Syntax) {
+		return fmt.Errorf("admonitions.syntax must be 'preserve', 'github', or 'mkdocs'")
+	}
+	if c.Admonitions.Syntax == AdmonitionSyntaxGitHub && !c.Markdown.HasExtension(ExtensionAlerts) {
+		return fmt.Errorf("admonitions.syntax 'github' requires the '%s' extension", ExtensionAlerts)
+	}
+	if c.Admonitions.Syntax == AdmonitionSyntaxMkDocs && !c.Markdown.HasExtension(ExtensionAdmonitions) {
+		return fmt.Errorf("admonitions.syntax 'mkdocs' requires the '%s' extension", ExtensionAdmonitions)
+	}
+
 	if c.Whitespace.MaxBlankLines < 0 {
 		return fmt.Errorf("whitespace.max_blank_lines must be >= 0")
 	}
 
+	if !contains([]string{HardBreakPreserve, HardBreakBackslash, HardBreakSpaces}, c.Whitespace.HardBreak) {
+		return fmt.Errorf("whitespace.hard_break must be 'preserve', 'backslash', or 'spaces'")
+	}
+
+	return nil
+}
+
+// validate checks the dialect preset or extension names
+func (m MarkdownConfig) validate() error {
+	if m.Dialect.Preset != "" {
+		if _, ok := dialectPresets[m.Dialect.Preset]; !ok {
+			return fmt.Errorf("markdown.dialect must be 'commonmark', 'gfm', 'extended', or a list of extensions")
+		}
+		return nil
+	}
+	for _, name := range m.Dialect.Extensions {
+		if !contains(syntaxExtensions, name) {
+			return fmt.Errorf("markdown.dialect: unknown extension %q, must be one of %s",
+				name, strings.Join(syntaxExtensions, ", "))
+		}
+	}
 	return nil
 }
 
@@ -212,6 +661,12 @@ func (c *Config) IsMarkdownFile(filename string) bool {
 	return contains(c.Files.Extensions, ext)
 }
 
+// IsMDXFile checks if a file is parsed as MDX, based on its extension or the
+// markdown.mdx setting
+func (c *Config) IsMDXFile(filename string) bool {
+	return c.Markdown.MDX || strings.ToLower(filepath.Ext(filename)) == MDXExtension
+}
+
 // ShouldIgnore checks if a file should be ignored based on patterns.
 func (c *Config) ShouldIgnore(path string) bool {
 	path = filepath.Clean(path)
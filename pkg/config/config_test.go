@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -59,6 +60,144 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid table style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Table.Style = "grid"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid thematic break style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.ThematicBreak.Style = "==="
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "thematic break length too short",
+			config: func() *Config {
+				cfg := Default()
+				cfg.ThematicBreak.Length = 2
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "front matter indent too small",
+			config: func() *Config {
+				cfg := Default()
+				cfg.FrontMatter.Normalize = true
+				cfg.FrontMatter.Indent = 0
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid list spacing",
+			config: func() *Config {
+				cfg := Default()
+				cfg.List.Spacing = "compact"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid ordered numbering",
+			config: func() *Config {
+				cfg := Default()
+				cfg.List.OrderedNumbering = "roman"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid bare URL style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Links.BareURLs = "html"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid link title quote",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Links.TitleQuote = "<>"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid admonition syntax",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Admonitions.Syntax = "rst"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid dialect",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Markdown.Dialect = Dialect{Preset: "markdown"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "unknown dialect extension",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Markdown.Dialect = Dialect{Extensions: []string{ExtensionTables, "emoji"}}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "explicit heading IDs without attributes",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Markdown.Dialect = Dialect{Preset: DialectCommonMark}
+				cfg.Heading.ExplicitIDs = true
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "MkDocs admonitions without the extension",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Markdown.Dialect = Dialect{Extensions: []string{ExtensionAlerts}}
+				cfg.Admonitions.Syntax = AdmonitionSyntaxMkDocs
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid hard break style",
+			config: func() *Config {
+				cfg := Default()
+				cfg.Whitespace.HardBreak = "html"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid link reference placement",
+			config: func() *Config {
+				cfg := Default()
+				cfg.LinkReferences.Placement = "top"
+				return cfg
+			}(),
+			wantErr: true,
+		},
 		{
 			name: "invalid bullet style",
 			config: &Config{
@@ -125,6 +264,99 @@ code:
 	}
 }
 
+func TestDialect_ExtensionNames(t *testing.T) {
+	// GFM is CommonMark with the extensions github.com renders, and nothing more
+	gfm := []string{
+		ExtensionTables, ExtensionStrikethrough, ExtensionTaskLists, ExtensionAutolinks, ExtensionFootnotes,
+		ExtensionAlerts,
+	}
+	extended := []string{
+		ExtensionTables, ExtensionStrikethrough, ExtensionTaskLists, ExtensionAutolinks, ExtensionFootnotes,
+		ExtensionMath, ExtensionAlerts, ExtensionAdmonitions, ExtensionHeadingAttributes,
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected []string
+	}{
+		{"zero value", Dialect{}, gfm},
+		{"gfm", Dialect{Preset: DialectGFM}, gfm},
+		{"commonmark", Dialect{Preset: DialectCommonMark}, []string{}},
+		{"extended", Dialect{Preset: DialectExtended}, extended},
+		{"list", Dialect{Extensions: []string{ExtensionMath}}, []string{ExtensionMath}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.ExtensionNames(); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected extensions %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_Dialect(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{"line_width: 80\n", []string{
+			ExtensionTables, ExtensionStrikethrough, ExtensionTaskLists, ExtensionAutolinks, ExtensionFootnotes,
+			ExtensionAlerts,
+		}},
+		{"markdown:\n  dialect: extended\n", []string{
+			ExtensionTables, ExtensionStrikethrough, ExtensionTaskLists, ExtensionAutolinks, ExtensionFootnotes,
+			ExtensionMath, ExtensionAlerts, ExtensionAdmonitions, ExtensionHeadingAttributes,
+		}},
+		{"markdown:\n  dialect: commonmark\n", []string{}},
+		{"markdown:\n  dialect: commonmark\n  definition_lists: true\n", []string{ExtensionDefinitionLists}},
+		{"markdown:\n  dialect: [tables, footnotes]\n", []string{ExtensionTables, ExtensionFootnotes}},
+		{"markdown:\n  dialect: []\n", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "dialect.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg := Default()
+			if err := cfg.LoadFromFile(configFile); err != nil {
+				t.Fatalf("LoadFromFile failed: %v", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			if got := cfg.Markdown.Extensions(); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected extensions %v, got %v", tt.expected, got)
+			}
+
+			// The dialect is saved in the form it was loaded from
+			if err := cfg.SaveToFile(configFile); err != nil {
+				t.Fatalf("SaveToFile failed: %v", err)
+			}
+			loaded := Default()
+			if err := loaded.LoadFromFile(configFile); err != nil {
+				t.Fatalf("Failed to load saved config: %v", err)
+			}
+			if got := loaded.Markdown.Extensions(); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected saved extensions %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	cfg := Default()
+	configFile := filepath.Join(t.TempDir(), "dialect.yaml")
+	if err := os.WriteFile(configFile, []byte("markdown:\n  dialect: {tables: true}\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := cfg.LoadFromFile(configFile); err == nil {
+		t.Error("Expected error for a dialect mapping, got nil")
+	}
+}
+
 func TestLoadFromFile_NotFound(t *testing.T) {
 	cfg := Default()
 	err := cfg.LoadFromFile("nonexistent.yaml")
@@ -193,6 +425,7 @@ func TestIsMarkdownFile(t *testing.T) {
 		{"README.md", true},
 		{"doc.markdown", true},
 		{"file.mdown", true},
+		{"page.mdx", true},
 		{"script.js", false},
 		{"style.css", false},
 		{"README.MD", true}, // case insensitive
@@ -209,6 +442,34 @@ func TestIsMarkdownFile(t *testing.T) {
 	}
 }
 
+func TestIsMDXFile(t *testing.T) {
+	cfg := Default()
+
+	tests := []struct {
+		filename string
+		expected bool
+	}{
+		{"page.mdx", true},
+		{"PAGE.MDX", true}, // case insensitive
+		{"README.md", false},
+		{"notes.mdx.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			result := cfg.IsMDXFile(tt.filename)
+			if result != tt.expected {
+				t.Errorf("IsMDXFile(%s) = %v, expected %v", tt.filename, result, tt.expected)
+			}
+		})
+	}
+
+	cfg.Markdown.MDX = true
+	if !cfg.IsMDXFile("README.md") {
+		t.Error("Expected every file to be parsed as MDX with markdown.mdx set")
+	}
+}
+
 func TestShouldIgnore(t *testing.T) {
 	cfg := Default()
 
@@ -0,0 +1,79 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// AdmonitionFormatter writes GitHub alerts and MkDocs admonitions in the
+// configured syntax and reports kinds their syntax does not define
+type AdmonitionFormatter struct {
+	BaseFormatter
+}
+
+// NewAdmonitionFormatter creates a new admonition formatter
+func NewAdmonitionFormatter() *AdmonitionFormatter {
+	return &AdmonitionFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "admonition",
+			priority: AdmonitionFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for admonitions
+func (f *AdmonitionFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeAdmonition
+}
+
+// Check reports admonitions whose kind is not one of those their syntax
+// defines. GitHub renders such alerts as plain blockquotes.
+func (f *AdmonitionFormatter) Check(node parser.Node, _ *config.Config) []Diagnostic {
+	admonition, ok := node.(*parser.Admonition)
+	if !ok || parser.IsKnownAdmonitionKind(admonition.Syntax, admonition.Kind) {
+		return nil
+	}
+
+	return []Diagnostic{{
+		Formatter: f.Name(),
+		Message:   fmt.Sprintf("unknown %s admonition kind %q", admonition.Syntax, admonition.Kind),
+		Pos:       admonition.Pos(),
+	}}
+}
+
+// Format converts an admonition to the configured syntax. Admonitions the
+// target syntax cannot express, such as MkDocs admonitions with a title or
+// a kind GitHub does not know, are left as they are.
+func (f *AdmonitionFormatter) Format(node parser.Node, cfg *config.Config) error {
+	admonition, ok := node.(*parser.Admonition)
+	if !ok {
+		return nil
+	}
+
+	switch cfg.Admonitions.Syntax {
+	case config.AdmonitionSyntaxGitHub:
+		if admonition.Syntax == parser.AdmonitionSyntaxMkDocs && isPlainMkDocsAdmonition(admonition) &&
+			parser.IsKnownAdmonitionKind(parser.AdmonitionSyntaxGitHub, admonition.Kind) {
+			admonition.Syntax = parser.AdmonitionSyntaxGitHub
+			admonition.Marker = ""
+			admonition.Kind = strings.ToUpper(admonition.Kind)
+		}
+	case config.AdmonitionSyntaxMkDocs:
+		if admonition.Syntax == parser.AdmonitionSyntaxGitHub &&
+			parser.IsKnownAdmonitionKind(parser.AdmonitionSyntaxGitHub, admonition.Kind) {
+			admonition.Syntax = parser.AdmonitionSyntaxMkDocs
+			admonition.Marker = parser.MkDocsAdmonitionMarker
+			admonition.Kind = strings.ToLower(admonition.Kind)
+		}
+	}
+	return nil
+}
+
+// isPlainMkDocsAdmonition reports whether a MkDocs admonition has nothing a
+// GitHub alert cannot express: it is not collapsible and has the default title
+func isPlainMkDocsAdmonition(admonition *parser.Admonition) bool {
+	return admonition.Marker == parser.MkDocsAdmonitionMarker && admonition.Title == "" && !admonition.HideTitle
+}
@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+func TestAdmonitionFormatter_Syntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		syntax   string
+		content  string
+		expected string
+	}{
+		{
+			name:   "github to mkdocs",
+			syntax: config.AdmonitionSyntaxMkDocs,
+			content: "> [!NOTE]\n> Body *text*.\n\n" +
+				"> [!tip]\n> - item\n\n" +
+				"> [!CUSTOM]\n> Unknown kinds stay alerts.\n\n" +
+				"> [!WARNING] Custom title\n> An ordinary blockquote.\n",
+			expected: "!!! note\n    Body *text*.\n\n" +
+				"!!! tip\n    - item\n\n" +
+				"> [!CUSTOM]\n> Unknown kinds stay alerts.\n\n" +
+				"> [!WARNING] Custom title An ordinary blockquote.\n",
+		},
+		{
+			name:   "mkdocs to github",
+			syntax: config.AdmonitionSyntaxGitHub,
+			content: "!!! Note\n    Body *text*.\n\n    Second paragraph.\n\n" +
+				"!!! warning \"Careful\"\n    Titled.\n\n" +
+				"??? tip\n    Collapsible.\n\n" +
+				"!!! danger\n    Not a GitHub kind.\n",
+			expected: "> [!NOTE]\n> Body *text*.\n>\n> Second paragraph.\n\n" +
+				"!!! warning \"Careful\"\n    Titled.\n\n" +
+				"??? tip\n    Collapsible.\n\n" +
+				"!!! danger\n    Not a GitHub kind.\n",
+		},
+		{
+			name:     "preserve",
+			syntax:   config.AdmonitionSyntaxPreserve,
+			content:  "> [!NOTE]\n> Alert.\n\n!!! note\n    Admonition.\n",
+			expected: "> [!NOTE]\n> Alert.\n\n!!! note\n    Admonition.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Markdown.Dialect = config.Dialect{Preset: config.DialectExtended}
+			cfg.Admonitions.Syntax = tt.syntax
+
+			got := format(t, cfg, tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if again := format(t, cfg, got); again != got {
+				t.Errorf("Expected the output to be stable, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestAdmonitionFormatter_UnknownKinds(t *testing.T) {
+	cfg := config.Default()
+	cfg.Markdown.Dialect = config.Dialect{Preset: config.DialectExtended}
+
+	content := "> [!NOTE]\n> Known.\n\n> [!CUSTOM]\n> Unknown.\n\n" +
+		"!!! tip\n    Known.\n\n!!! madeup extra\n    Unknown.\n"
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	engine := New()
+	if err := engine.Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := []string{
+		`unknown github admonition kind "CUSTOM"`,
+		`unknown mkdocs admonition kind "madeup extra"`,
+	}
+	diagnostics := engine.Diagnostics()
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), diagnostics)
+	}
+	for i, message := range expected {
+		if diagnostics[i].Formatter != "admonition" || diagnostics[i].Message != message {
+			t.Errorf("Expected %q from the admonition formatter, got %v", message, diagnostics[i])
+		}
+	}
+	if line := diagnostics[1].Pos.Line; line != 10 {
+		t.Errorf("Expected the MkDocs admonition on line 10, got %d", line)
+	}
+}
@@ -0,0 +1,126 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+const (
+	// schemeSeparator separates the scheme of a URL from the rest
+	schemeSeparator = "://"
+	// defaultURLScheme is the scheme of bare URLs written without one (www.example.com)
+	defaultURLScheme = "http"
+	// mailtoScheme is the scheme of email links
+	mailtoScheme = "mailto:"
+)
+
+// AutolinkFormatter converts GFM bare URLs into autolinks or inline links
+type AutolinkFormatter struct {
+	BaseFormatter
+}
+
+// NewAutolinkFormatter creates a new autolink formatter
+func NewAutolinkFormatter() *AutolinkFormatter {
+	return &AutolinkFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "autolink",
+			priority: AutolinkFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since converting a bare URL into a
+// link replaces it among its siblings
+func (f *AutolinkFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Format writes every bare URL in the configured style
+func (f *AutolinkFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || cfg.Links.BareURLs == config.BareURLsPreserve {
+		return nil
+	}
+
+	parser.Visit(doc, func(c *parser.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *parser.Link, *parser.Image:
+			// Link text is never linkified
+			return false
+		case *parser.Heading:
+			// Link text would change the generated ID of the heading
+			return cfg.Links.BareURLs != config.BareURLsLink
+		case *parser.Autolink:
+			if !n.Bare {
+				return false
+			}
+			switch cfg.Links.BareURLs {
+			case config.BareURLsAutolink:
+				if !n.Email {
+					n.URL = autolinkDestination(n)
+				}
+				n.Bare = false
+			case config.BareURLsLink:
+				c.Replace(bareURLLink(n))
+			}
+		}
+		return true
+	}, nil)
+
+	return nil
+}
+
+// autolinkDestination returns the URL a bare URL links to. GFM links bare
+// URLs without a scheme (www.example.com) over http.
+func autolinkDestination(autolink *parser.Autolink) string {
+	switch {
+	case autolink.Email:
+		return mailtoScheme + autolink.URL
+	case !strings.Contains(autolink.URL, schemeSeparator):
+		return defaultURLScheme + schemeSeparator + autolink.URL
+	}
+	return autolink.URL
+}
+
+// bareURLLink returns an inline link to a bare URL, labeled with the URL
+// without its scheme and trailing slash
+func bareURLLink(autolink *parser.Autolink) *parser.Link {
+	text := autolink.URL
+	if _, rest, found := strings.Cut(text, schemeSeparator); found {
+		text = rest
+	}
+	text = strings.TrimSuffix(text, "/")
+
+	link := &parser.Link{
+		Children:    []parser.Node{&parser.Text{Content: escapeLinkText(text)}},
+		Destination: autolinkDestination(autolink),
+	}
+	link.SetRange(autolink.Pos(), autolink.End())
+	return link
+}
+
+// escapeLinkText escapes the characters of a URL that would be read as markup
+// in link text. Underscores inside words cannot emphasize and are kept as is.
+func escapeLinkText(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\', '`', '*', '[', ']', '<':
+			sb.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(text)-1 || !isAlphanumeric(text[i-1]) || !isAlphanumeric(text[i+1]) {
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
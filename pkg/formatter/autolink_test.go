@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestAutolinkFormatter_BareURLs(t *testing.T) {
+	// URLs in code, link text, HTML attributes and HTML blocks are not bare URLs
+	content := "See https://example.com/a?b=1 and www.example.com, or mail me@example.com.\n\n" +
+		"Not `https://in.code`, [https://in.link](https://x.y) or <a href=\"https://in.html\">this</a>.\n\n" +
+		"<div>\nhttps://in.block\n</div>\n\n" +
+		"## Docs at https://docs.example\n"
+
+	tests := []struct {
+		style    string
+		expected string
+	}{
+		{
+			style:    config.BareURLsPreserve,
+			expected: content,
+		},
+		{
+			style: config.BareURLsAutolink,
+			expected: "See <https://example.com/a?b=1> and <http://www.example.com>, or mail <me@example.com>.\n\n" +
+				"Not `https://in.code`, [https://in.link](https://x.y) or <a href=\"https://in.html\">this</a>.\n\n" +
+				"<div>\nhttps://in.block\n</div>\n\n" +
+				"## Docs at <https://docs.example>\n",
+		},
+		{
+			// Headings keep their bare URLs, as link text would change their anchors
+			style: config.BareURLsLink,
+			expected: "See [example.com/a?b=1](https://example.com/a?b=1) and [www.example.com](http://www.example.com), " +
+				"or mail [me@example.com](mailto:me@example.com).\n\n" +
+				"Not `https://in.code`, [https://in.link](https://x.y) or <a href=\"https://in.html\">this</a>.\n\n" +
+				"<div>\nhttps://in.block\n</div>\n\n" +
+				"## Docs at https://docs.example\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.LineWidth = 200
+			cfg.Links.BareURLs = tt.style
+
+			got := format(t, cfg, content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if again := format(t, cfg, got); again != got {
+				t.Errorf("Expected the output to be stable, got:\n%s", again)
+			}
+		})
+	}
+}
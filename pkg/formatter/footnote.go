@@ -0,0 +1,203 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// footnoteReferencePattern matches a footnote reference such as [^note].
+// References to undefined footnotes are parsed as text, so this is how they are found.
+var footnoteReferencePattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// FootnoteFormatter renumbers footnotes sequentially in order of first reference
+type FootnoteFormatter struct {
+	BaseFormatter
+}
+
+// NewFootnoteFormatter creates a new footnote formatter
+func NewFootnoteFormatter() *FootnoteFormatter {
+	return &FootnoteFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "footnote",
+			priority: FootnoteFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since footnotes span the whole document
+func (f *FootnoteFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Check reports references without a definition and definitions that are never referenced
+func (f *FootnoteFormatter) Check(node parser.Node, cfg *config.Config) []Diagnostic {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Footnotes.Renumber {
+		return nil
+	}
+
+	definitions := footnoteDefinitions(doc)
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Label] = true
+	}
+
+	var diagnostics []Diagnostic
+	referenced := make(map[string]bool)
+	for _, reference := range footnoteReferences(doc) {
+		referenced[reference.label] = true
+		if !defined[reference.label] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Formatter: f.Name(),
+				Message:   fmt.Sprintf("footnote reference [^%s] has no definition", reference.label),
+				Pos:       reference.pos,
+			})
+		}
+	}
+
+	for _, definition := range definitions {
+		if !referenced[definition.Label] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Formatter: f.Name(),
+				Message:   fmt.Sprintf("footnote definition [^%s] is never referenced", definition.Label),
+				Pos:       definition.Pos(),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// Format relabels footnotes 1, 2, 3... in order of first reference. Unused
+// definitions are kept and numbered after the referenced ones, and all
+// definitions are moved to the end of the document in numeric order.
+func (f *FootnoteFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok || !cfg.Footnotes.Renumber {
+		return nil
+	}
+
+	definitions := footnoteDefinitions(doc)
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Label] = true
+	}
+
+	numbers := make(map[string]string)
+	for _, reference := range footnoteReferences(doc) {
+		if _, seen := numbers[reference.label]; defined[reference.label] && !seen {
+			numbers[reference.label] = strconv.Itoa(len(numbers) + 1)
+		}
+	}
+	for _, definition := range definitions {
+		if _, seen := numbers[definition.Label]; !seen {
+			numbers[definition.Label] = strconv.Itoa(len(numbers) + 1)
+		}
+	}
+
+	walkNodes(doc.Children, func(node parser.Node) {
+		if reference, ok := node.(*parser.FootnoteReference); ok {
+			if number, ok := numbers[reference.Label]; ok {
+				reference.Label = number
+			}
+		}
+	})
+
+	for _, definition := range definitions {
+		definition.Label = numbers[definition.Label]
+	}
+	sort.SliceStable(definitions, func(i, j int) bool {
+		a, _ := strconv.Atoi(definitions[i].Label)
+		b, _ := strconv.Atoi(definitions[j].Label)
+		return a < b
+	})
+
+	removeNodes(doc, func(node parser.Node) bool {
+		return node.Type() == parser.NodeFootnoteDefinition
+	})
+	for _, definition := range definitions {
+		doc.Children = append(doc.Children, definition)
+	}
+
+	return nil
+}
+
+// footnoteDefinitions returns all footnote definitions in document order,
+// wherever they are nested
+func footnoteDefinitions(doc *parser.Document) []*parser.FootnoteDefinition {
+	var definitions []*parser.FootnoteDefinition
+	parser.Inspect(doc, func(node parser.Node) bool {
+		if definition, ok := node.(*parser.FootnoteDefinition); ok {
+			definitions = append(definitions, definition)
+		}
+		return true
+	})
+	return definitions
+}
+
+// footnoteReference is a footnote reference found in a document
+type footnoteReference struct {
+	label string
+	pos   parser.Position
+}
+
+// footnoteReferences returns all footnote references in the order they are
+// read: the document body first, then the definitions
+func footnoteReferences(doc *parser.Document) []footnoteReference {
+	var references []footnoteReference
+	collect := func(node parser.Node) {
+		switch n := node.(type) {
+		case *parser.FootnoteReference:
+			references = append(references, footnoteReference{label: n.Label, pos: n.Pos()})
+		case *parser.Text:
+			for _, match := range footnoteReferencePattern.FindAllStringSubmatchIndex(n.Content, -1) {
+				references = append(references, footnoteReference{
+					label: n.Content[match[2]:match[3]],
+					pos:   textPosition(n, match[0]),
+				})
+			}
+		}
+	}
+
+	// Definitions nested in the body or in other definitions are read on their own
+	collectOutsideDefinitions := func(root parser.Node) {
+		parser.Inspect(root, func(node parser.Node) bool {
+			if node != root && node.Type() == parser.NodeFootnoteDefinition {
+				return false
+			}
+			collect(node)
+			return true
+		})
+	}
+
+	collectOutsideDefinitions(doc)
+	for _, definition := range footnoteDefinitions(doc) {
+		collectOutsideDefinitions(definition)
+	}
+	return references
+}
+
+// textPosition returns the source position of the byte at offset in the
+// content of a text node. Text nodes never span lines, but escapes and
+// entities make the content shorter than the source, so the position is
+// only exact when the lengths agree.
+func textPosition(text *parser.Text, offset int) parser.Position {
+	start := text.Pos()
+	if !start.IsValid() || text.End().Offset-start.Offset != len(text.Content) {
+		return start
+	}
+	return parser.Position{
+		Offset: start.Offset + offset,
+		Line:   start.Line,
+		Column: start.Column + offset,
+	}
+}
@@ -0,0 +1,76 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+	"github.com/Gosayram/go-mdfmt/pkg/renderer"
+)
+
+// format parses content, applies the default formatters and renders the
+// result with cfg
+func format(t *testing.T, cfg *config.Config, content string) string {
+	t.Helper()
+
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := New().Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output, err := renderer.New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	// Only the content is compared, not the blank line after the last block
+	return strings.TrimRight(output, "\n") + "\n"
+}
+
+func TestFootnoteFormatter_RenumberNestedDefinitions(t *testing.T) {
+	cfg := config.Default()
+	cfg.Footnotes.Renumber = true
+	cfg.Markdown.DefinitionLists = true
+
+	content := `Text[^x] and[^y].
+
+- item
+
+  [^y]: In a list item.
+
+Term
+: Definition
+
+  [^x]: In a definition.
+
+> [^z]: Alone in a quote.
+`
+	expected := `Text[^1] and[^2].
+
+- item
+
+Term
+: Definition
+
+[^1]: In a definition.
+
+[^2]: In a list item.
+
+[^3]: Alone in a quote.
+`
+
+	if got := format(t, cfg, content); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	diagnostics := NewFootnoteFormatter().Check(doc, cfg)
+	if len(diagnostics) != 1 || diagnostics[0].Message != "footnote definition [^z] is never referenced" {
+		t.Errorf("Expected only [^z] to be reported, got %v", diagnostics)
+	}
+}
@@ -3,7 +3,7 @@ package formatter
 
 import (
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/Gosayram/go-mdfmt/pkg/config"
@@ -23,11 +23,27 @@ const (
 	WhitespaceFormatterPriority = 10
 	// InlineFormatterPriority defines the priority for inline formatting
 	InlineFormatterPriority = 60
+	// TableFormatterPriority defines the priority for table formatting
+	TableFormatterPriority = 75
+	// ThematicBreakFormatterPriority defines the priority for thematic break formatting
+	ThematicBreakFormatterPriority = 65
+	// FrontMatterFormatterPriority defines the priority for front matter formatting
+	FrontMatterFormatterPriority = 110
+	// FootnoteFormatterPriority defines the priority for footnote formatting
+	FootnoteFormatterPriority = 105
+	// LinkReferenceFormatterPriority defines the priority for link reference definition formatting
+	LinkReferenceFormatterPriority = 100
+	// AutolinkFormatterPriority defines the priority for bare URL formatting
+	AutolinkFormatterPriority = 95
+	// AdmonitionFormatterPriority defines the priority for admonition formatting
+	AdmonitionFormatterPriority = 85
 
 	// AtxHeadingStyle represents ATX-style heading format (# ## ###)
 	AtxHeadingStyle = "atx"
 	// SetextHeadingStyle represents setext-style heading format (underlined with = or -)
 	SetextHeadingStyle = "setext"
+	// AtxClosedHeadingStyle represents ATX-style headings with closing hashes (## Title ##)
+	AtxClosedHeadingStyle = "atx-closed"
 
 	// MinHeadingLevel defines the minimum allowed heading level
 	MinHeadingLevel = 1
@@ -35,6 +51,27 @@ const (
 	MaxHeadingLevel = 6
 	// SetextMaxLevel defines the maximum level for setext-style headings
 	SetextMaxLevel = 2
+
+	// BackslashHardBreak is the hard line break marker written as a backslash
+	BackslashHardBreak = "\\"
+	// SpaceHardBreak is the hard line break marker written as trailing spaces
+	SpaceHardBreak = "  "
+
+	// DefaultBullet is the bullet of a list that follows a list using the
+	// configured bullet, when that is AlternateBullet
+	DefaultBullet = "-"
+	// AlternateBullet is the bullet of a list that follows a list using the
+	// configured bullet
+	AlternateBullet = "*"
+	// PeriodDelimiter is the ordered list delimiter in 1.
+	PeriodDelimiter = "."
+	// ParenthesisDelimiter is the ordered list delimiter in 1)
+	ParenthesisDelimiter = ")"
+
+	// AsteriskEmphasis is the emphasis delimiter emphasis is normalized to
+	AsteriskEmphasis = "*"
+	// UnderscoreEmphasis is the emphasis delimiter replaced where asterisks fit
+	UnderscoreEmphasis = "_"
 )
 
 // Formatter represents a markdown formatter
@@ -55,9 +92,31 @@ type NodeFormatter interface {
 	Priority() int
 }
 
+// Checker is implemented by formatters that can report problems they do not
+// fix. Check is called before Format with the unmodified node.
+type Checker interface {
+	Check(node parser.Node, cfg *config.Config) []Diagnostic
+}
+
+// Diagnostic describes a problem found in a document during formatting
+type Diagnostic struct {
+	// Formatter is the name of the formatter that reported the problem
+	Formatter string
+	// Message describes the problem
+	Message string
+	// Pos is where the problem is in the source, if known
+	Pos parser.Position
+}
+
+// String returns a human readable representation of the diagnostic
+func (d Diagnostic) String() string {
+	return d.Formatter + ": " + d.Message
+}
+
 // Engine represents the main formatting engine
 type Engine struct {
-	formatters []NodeFormatter
+	formatters  []NodeFormatter
+	diagnostics []Diagnostic
 }
 
 // New creates a new formatting engine with default formatters
@@ -69,10 +128,17 @@ func New() *Engine {
 
 // RegisterDefaults registers the default formatters
 func (e *Engine) RegisterDefaults() {
-	e.Register(&HeadingFormatter{})
+	e.Register(NewFrontMatterFormatter())
+	e.Register(NewFootnoteFormatter())
+	e.Register(NewLinkReferenceFormatter())
+	e.Register(NewAutolinkFormatter())
+	e.Register(NewAdmonitionFormatter())
+	e.Register(NewHeadingFormatter())
 	e.Register(&ParagraphFormatter{})
 	e.Register(&ListFormatter{})
 	e.Register(&CodeBlockFormatter{})
+	e.Register(NewTableFormatter())
+	e.Register(NewThematicBreakFormatter())
 	e.Register(&InlineFormatter{})
 	e.Register(&WhitespaceFormatter{})
 }
@@ -90,17 +156,37 @@ func (e *Engine) Register(formatter NodeFormatter) {
 	}
 }
 
-// Format formats the given AST according to configuration
+// Format formats the given AST according to configuration. Every node of the
+// document is visited, down to the inline nodes.
 func (e *Engine) Format(doc *parser.Document, cfg *config.Config) error {
-	walker := parser.NewWalker(doc)
+	e.diagnostics = nil
 
-	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
-		for _, formatter := range e.formatters {
-			if formatter.CanFormat(node.Type()) {
-				if err := formatter.Format(node, cfg); err != nil {
-					return err
-				}
-				break // Only apply first matching formatter
+	var err error
+	parser.Inspect(doc, func(node parser.Node) bool {
+		if err != nil || isVerbatim(node) {
+			return false
+		}
+		err = e.formatNode(node, cfg)
+		return err == nil
+	})
+
+	return err
+}
+
+// formatNode applies the first matching formatter to a node. Its children
+// are visited afterwards, so they see the changes made to the node.
+func (e *Engine) formatNode(node parser.Node, cfg *config.Config) error {
+	e.check(node, cfg)
+
+	for _, formatter := range e.formatters {
+		if formatter.CanFormat(node.Type()) {
+			if err := formatter.Format(node, cfg); err != nil {
+				return err
+			}
+			// Only apply first matching formatter. Document formatters each
+			// handle a separate concern across the document, so all of them run.
+			if node.Type() != parser.NodeDocument {
+				break
 			}
 		}
 	}
@@ -108,6 +194,62 @@ func (e *Engine) Format(doc *parser.Document, cfg *config.Config) error {
 	return nil
 }
 
+// walkNodes calls fn for the nodes and all of their descendants in document order
+func walkNodes(nodes []parser.Node, fn func(parser.Node)) {
+	for _, node := range nodes {
+		parser.Inspect(node, func(node parser.Node) bool {
+			fn(node)
+			return true
+		})
+	}
+}
+
+// removeNodes deletes every node for which remove returns true from the
+// tree rooted at root, along with the blockquotes that only held such nodes
+func removeNodes(root parser.Node, remove func(parser.Node) bool) {
+	filled := make(map[*parser.Blockquote]bool)
+	parser.Visit(root, func(c *parser.Cursor) bool {
+		if remove(c.Node()) {
+			c.Delete()
+			return false
+		}
+		if quote, ok := c.Node().(*parser.Blockquote); ok {
+			filled[quote] = len(quote.Children) > 0
+		}
+		return true
+	}, func(c *parser.Cursor) bool {
+		if quote, ok := c.Node().(*parser.Blockquote); ok && filled[quote] && len(quote.Children) == 0 {
+			c.Delete()
+		}
+		return true
+	})
+}
+
+// check collects diagnostics from every checker that handles the node
+func (e *Engine) check(node parser.Node, cfg *config.Config) {
+	for _, formatter := range e.formatters {
+		checker, ok := formatter.(Checker)
+		if ok && formatter.CanFormat(node.Type()) {
+			e.diagnostics = append(e.diagnostics, checker.Check(node, cfg)...)
+		}
+	}
+}
+
+// Diagnostics returns the problems reported during the last call to Format
+func (e *Engine) Diagnostics() []Diagnostic {
+	return e.diagnostics
+}
+
+// isVerbatim reports whether a node must be written back exactly as it was
+// read, in which case no formatter is allowed to see it
+func isVerbatim(node parser.Node) bool {
+	switch node.Type() {
+	case parser.NodeHTMLBlock, parser.NodeMathBlock, parser.NodeESM:
+		return true
+	}
+	return false
+}
+
 // BaseFormatter provides common functionality for formatters
 type BaseFormatter struct {
 	name     string
@@ -139,30 +281,68 @@ func NewHeadingFormatter() *HeadingFormatter {
 	}
 }
 
-// CanFormat returns true if this formatter can handle headings
+// CanFormat returns true for headings, and for documents, where the style
+// of the first heading is applied to all others in "consistent" mode
 func (f *HeadingFormatter) CanFormat(nodeType parser.NodeType) bool {
-	return nodeType == parser.NodeHeading
+	return nodeType == parser.NodeHeading || nodeType == parser.NodeDocument
+}
+
+// Check reports headings written in a different style than the first one
+// when heading styles are preserved or made consistent
+func (f *HeadingFormatter) Check(node parser.Node, cfg *config.Config) []Diagnostic {
+	doc, ok := node.(*parser.Document)
+	if !ok || (cfg.Heading.Style != config.HeadingStylePreserve && cfg.Heading.Style != config.HeadingStyleConsistent) {
+		return nil
+	}
+
+	headings := documentHeadings(doc)
+	if len(headings) == 0 {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	first := headings[0].Style
+	for _, heading := range headings[1:] {
+		if headingStyleFor(heading, first) != heading.Style {
+			diagnostics = append(diagnostics, Diagnostic{
+				Formatter: f.Name(),
+				Message: fmt.Sprintf("heading %q uses %s style, but the first heading uses %s",
+					strings.TrimSpace(heading.Text()), heading.Style, first),
+				Pos: heading.Pos(),
+			})
+		}
+	}
+	return diagnostics
 }
 
 // Format applies heading formatting rules.
 func (f *HeadingFormatter) Format(node parser.Node, cfg *config.Config) error {
+	if doc, ok := node.(*parser.Document); ok {
+		if headings := documentHeadings(doc); cfg.Heading.Style == config.HeadingStyleConsistent && len(headings) > 0 {
+			first := headings[0].Style
+			for _, heading := range headings {
+				heading.Style = headingStyleFor(heading, first)
+			}
+		}
+		return nil
+	}
+
 	heading, ok := node.(*parser.Heading)
 	if !ok {
 		return nil
 	}
 
-	// Apply heading style preferences
-	if cfg.Heading.Style == AtxHeadingStyle {
-		// Ensure ATX-style headers (#, ##, ###, etc.)
+	// Apply heading style preferences. "preserve" and "consistent" keep the
+	// style the heading already has.
+	switch cfg.Heading.Style {
+	case config.HeadingStyleATX:
 		heading.Style = AtxHeadingStyle
-	} else if cfg.Heading.Style == SetextHeadingStyle {
-		// Use setext style for levels 1 and 2, ATX for others
-		if heading.Level <= SetextMaxLevel {
-			heading.Style = SetextHeadingStyle
-		} else {
-			heading.Style = AtxHeadingStyle
-		}
+	case config.HeadingStyleATXClosed:
+		heading.Style = AtxClosedHeadingStyle
+	case config.HeadingStyleSetext:
+		heading.Style = SetextHeadingStyle
 	}
+	heading.Style = headingStyleFor(heading, heading.Style)
 
 	// Apply heading level normalization if enabled
 	if cfg.Heading.NormalizeLevels {
@@ -176,12 +356,39 @@ func (f *HeadingFormatter) Format(node parser.Node, cfg *config.Config) error {
 		}
 	}
 
-	// Clean up heading text (trim whitespace)
-	heading.Text = strings.TrimSpace(heading.Text)
+	// Clean up heading text (collapse and trim whitespace)
+	heading.Inlines = normalizeInlines(heading.Inlines)
+
+	// Pin the anchor generated from the current title
+	if cfg.Heading.ExplicitIDs && heading.ID == "" {
+		heading.ID = heading.AutoID
+	}
 
 	return nil
 }
 
+// headingStyleFor returns the style a heading can be written in when style is
+// wanted. Setext headings only exist for levels 1 and 2 and need text; other
+// headings use ATX instead.
+func headingStyleFor(heading *parser.Heading, style string) string {
+	if style == SetextHeadingStyle && (heading.Level > SetextMaxLevel || len(heading.Inlines) == 0) {
+		return AtxHeadingStyle
+	}
+	return style
+}
+
+// documentHeadings returns all headings of a document in document order,
+// including those in blockquotes and list items
+func documentHeadings(doc *parser.Document) []*parser.Heading {
+	var headings []*parser.Heading
+	walkNodes(doc.Children, func(node parser.Node) {
+		if heading, ok := node.(*parser.Heading); ok {
+			headings = append(headings, heading)
+		}
+	})
+	return headings
+}
+
 // ParagraphFormatter formats paragraph nodes
 type ParagraphFormatter struct {
 	BaseFormatter
@@ -192,72 +399,95 @@ func (f *ParagraphFormatter) CanFormat(nodeType parser.NodeType) bool {
 	return nodeType == parser.NodeParagraph
 }
 
-// Format applies paragraph formatting rules with text reflow.
-func (f *ParagraphFormatter) Format(node parser.Node, cfg *config.Config) error {
+// Format applies paragraph whitespace rules. Text is reflowed to the line
+// width when it is rendered, since only the renderer knows how wide the
+// inline markup is.
+func (f *ParagraphFormatter) Format(node parser.Node, _ *config.Config) error {
 	paragraph, ok := node.(*parser.Paragraph)
 	if !ok {
 		return nil
 	}
 
-	// Apply text reflow if line width is configured
-	if cfg.LineWidth > 0 {
-		paragraph.Text = f.wrapText(paragraph.Text, cfg.LineWidth)
-	}
-
-	// Clean up excessive whitespace
-	paragraph.Text = strings.TrimSpace(paragraph.Text)
-	// Replace multiple spaces with single space
-	paragraph.Text = normalizeWhitespace(paragraph.Text)
+	// Replace multiple spaces with single space and trim the ends
+	paragraph.Inlines = normalizeInlines(paragraph.Inlines)
 
 	return nil
 }
 
-// wrapText wraps text to the specified line width
-func (f *ParagraphFormatter) wrapText(text string, width int) string {
-	if width <= 0 {
-		return text
-	}
+// normalizeInlines collapses runs of spaces and tabs in the text of inline
+// nodes and trims whitespace and line breaks from both ends
+func normalizeInlines(inlines []parser.Node) []parser.Node {
+	collapseInlineWhitespace(inlines)
+	return trimInlines(inlines)
+}
 
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return text
+// collapseInlineWhitespace replaces runs of spaces and tabs in text nodes,
+// including those nested in emphasis and links, with a single space
+func collapseInlineWhitespace(inlines []parser.Node) {
+	for _, inline := range inlines {
+		if text, ok := inline.(*parser.Text); ok {
+			text.Content = collapseWhitespace(text.Content)
+			continue
+		}
+		collapseInlineWhitespace(parser.Children(inline))
 	}
+}
 
-	var lines []string
-	var currentLine strings.Builder
-
-	for i, word := range words {
-		// Check if adding this word would exceed the line width
-		if currentLine.Len() > 0 && currentLine.Len()+1+len(word) > width {
-			// Start a new line
-			lines = append(lines, currentLine.String())
-			currentLine.Reset()
+// collapseWhitespace replaces each run of spaces and tabs with a single space
+func collapseWhitespace(text string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range text {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
 		}
-
-		if currentLine.Len() > 0 {
-			currentLine.WriteString(" ")
+		if space {
+			sb.WriteByte(' ')
+			space = false
 		}
-		currentLine.WriteString(word)
-
-		// If this is the last word, add the current line
-		if i == len(words)-1 {
-			lines = append(lines, currentLine.String())
+		sb.WriteRune(r)
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// trimInlines removes leading and trailing whitespace and line breaks from
+// inline content. Text nodes left empty are dropped.
+func trimInlines(inlines []parser.Node) []parser.Node {
+	for len(inlines) > 0 {
+		switch first := inlines[0].(type) {
+		case *parser.SoftBreak, *parser.HardBreak:
+			inlines = inlines[1:]
+			continue
+		case *parser.Text:
+			first.Content = strings.TrimLeft(first.Content, " \t")
+			if first.Content == "" {
+				inlines = inlines[1:]
+				continue
+			}
 		}
+		break
 	}
 
-	return strings.Join(lines, "\n")
-}
-
-// normalizeWhitespace replaces multiple consecutive spaces with single spaces
-func normalizeWhitespace(text string) string {
-	// Replace multiple spaces/tabs with single space
-	lines := strings.Split(text, "\n")
-	for i, line := range lines {
-		// Replace multiple whitespace characters with single space
-		fields := strings.Fields(line)
-		lines[i] = strings.Join(fields, " ")
+	for len(inlines) > 0 {
+		switch last := inlines[len(inlines)-1].(type) {
+		case *parser.SoftBreak, *parser.HardBreak:
+			inlines = inlines[:len(inlines)-1]
+			continue
+		case *parser.Text:
+			last.Content = strings.TrimRight(last.Content, " \t")
+			if last.Content == "" {
+				inlines = inlines[:len(inlines)-1]
+				continue
+			}
+		}
+		break
 	}
-	return strings.Join(lines, "\n")
+
+	return inlines
 }
 
 // ListFormatter formats list nodes
@@ -267,12 +497,14 @@ type ListFormatter struct {
 
 // CanFormat returns true if this formatter can handle lists
 func (f *ListFormatter) CanFormat(nodeType parser.NodeType) bool {
-	return nodeType == parser.NodeList || nodeType == parser.NodeListItem
+	return nodeType == parser.NodeList || nodeType == parser.NodeListItem || nodeType == parser.NodeDocument
 }
 
 // Format applies list formatting rules.
 func (f *ListFormatter) Format(node parser.Node, cfg *config.Config) error {
 	switch n := node.(type) {
+	case *parser.Document:
+		f.chooseListMarkers(n, cfg)
 	case *parser.List:
 		return f.formatList(n, cfg)
 	case *parser.ListItem:
@@ -281,7 +513,52 @@ func (f *ListFormatter) Format(node parser.Node, cfg *config.Config) error {
 	return nil
 }
 
-// formatList handles formatting of list nodes
+// chooseListMarkers gives every list in the document the configured bullet
+// or delimiter. A list that directly follows a list of the same kind gets
+// the other marker instead, since lists with the same marker would merge.
+func (f *ListFormatter) chooseListMarkers(doc *parser.Document, cfg *config.Config) {
+	parser.Visit(doc, func(c *parser.Cursor) bool {
+		list, ok := c.Node().(*parser.List)
+		if !ok {
+			return true
+		}
+
+		marker, other := cfg.List.BulletStyle, AlternateBullet
+		if marker == AlternateBullet {
+			other = DefaultBullet
+		}
+		if list.Ordered {
+			marker, other = PeriodDelimiter, ParenthesisDelimiter
+			if cfg.List.NumberStyle == ParenthesisDelimiter {
+				marker, other = other, marker
+			}
+		}
+
+		if previous := previousList(c); previous != nil && previous.Ordered == list.Ordered && previous.Marker == marker {
+			marker = other
+		}
+		list.Marker = marker
+		return true
+	}, nil)
+}
+
+// previousList returns the list directly before the current node, if any.
+// Footnote definitions in between do not count, as they are written at the
+// end of the document.
+func previousList(c *parser.Cursor) *parser.List {
+	siblings := c.Siblings()
+	for i := c.Index() - 1; i >= 0; i-- {
+		if siblings[i].Type() == parser.NodeFootnoteDefinition {
+			continue
+		}
+		list, _ := siblings[i].(*parser.List)
+		return list
+	}
+	return nil
+}
+
+// formatList handles formatting of list nodes. The engine formats the items
+// and any nested lists afterwards.
 func (f *ListFormatter) formatList(list *parser.List, cfg *config.Config) error {
 	if !list.Ordered {
 		f.formatUnorderedList(list, cfg)
@@ -289,69 +566,49 @@ func (f *ListFormatter) formatList(list *parser.List, cfg *config.Config) error
 		f.formatOrderedList(list, cfg)
 	}
 
-	return f.processListItems(list, cfg)
+	switch cfg.List.Spacing {
+	case config.ListSpacingTight:
+		list.Tight = true
+	case config.ListSpacingLoose:
+		list.Tight = false
+	}
+	return nil
 }
 
-// formatUnorderedList sets consistent bullet style for unordered lists
-func (f *ListFormatter) formatUnorderedList(list *parser.List, cfg *config.Config) {
-	list.Marker = cfg.List.BulletStyle
-	// Apply the same marker to all items
+// formatUnorderedList gives all items the bullet chosen for the list
+func (f *ListFormatter) formatUnorderedList(list *parser.List, _ *config.Config) {
 	for _, item := range list.Items {
-		item.Marker = cfg.List.BulletStyle
+		item.Marker = list.Marker
 	}
 }
 
-// formatOrderedList sets consistent numbering for ordered lists
+// formatOrderedList numbers the items of an ordered list according to the
+// configured strategy, keeping the list's start number and the delimiter
+// chosen for the list
 func (f *ListFormatter) formatOrderedList(list *parser.List, cfg *config.Config) {
-	for i, item := range list.Items {
-		switch cfg.List.NumberStyle {
-		case ".":
-			item.Marker = fmt.Sprintf("%d.", i+1)
-		case ")":
-			item.Marker = fmt.Sprintf("%d)", i+1)
-		default:
-			item.Marker = fmt.Sprintf("%d.", i+1)
-		}
-	}
-}
-
-// processListItems handles list item processing and nested lists
-func (f *ListFormatter) processListItems(list *parser.List, cfg *config.Config) error {
-	for _, item := range list.Items {
-		if cfg.List.ConsistentIndentation {
-			// Normalize list item text (trim and clean whitespace)
-			item.Text = strings.TrimSpace(item.Text)
-			item.Text = normalizeWhitespace(item.Text)
-		}
+	delimiter := list.Marker
 
-		// Process nested lists recursively
-		if err := f.processNestedLists(item, cfg); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// processNestedLists handles nested lists within list items
-func (f *ListFormatter) processNestedLists(item *parser.ListItem, cfg *config.Config) error {
-	for _, child := range item.Children {
-		if childList, ok := child.(*parser.List); ok {
-			if err := f.Format(childList, cfg); err != nil {
-				return err
+	for i, item := range list.Items {
+		number := strconv.Itoa(list.Start + i)
+		switch cfg.List.OrderedNumbering {
+		case config.OrderedNumberingAllOnes:
+			number = strconv.Itoa(list.Start)
+		case config.OrderedNumberingPreserve:
+			if written := strings.TrimRight(item.Marker, ".)"); written != "" {
+				number = written
 			}
 		}
+		item.Marker = number + delimiter
 	}
-	return nil
 }
 
 // formatListItem handles formatting of individual list items
 func (f *ListFormatter) formatListItem(item *parser.ListItem, cfg *config.Config) error {
-	// Individual list item formatting
-	item.Text = strings.TrimSpace(item.Text)
-	item.Text = normalizeWhitespace(item.Text)
-
-	// Process nested lists in this item
-	return f.processNestedLists(item, cfg)
+	if cfg.List.ConsistentIndentation {
+		// Normalize list item text (trim and clean whitespace)
+		item.Inlines = normalizeInlines(item.Inlines)
+	}
+	return nil
 }
 
 // CodeBlockFormatter formats code block nodes
@@ -384,6 +641,101 @@ func (f *CodeBlockFormatter) Format(node parser.Node, cfg *config.Config) error
 	return nil
 }
 
+// TableFormatter formats table nodes
+type TableFormatter struct {
+	BaseFormatter
+}
+
+// NewTableFormatter creates a new table formatter
+func NewTableFormatter() *TableFormatter {
+	return &TableFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "table",
+			priority: TableFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true if this formatter can handle tables
+func (f *TableFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeTable
+}
+
+// Format normalizes cell contents and makes every row match the column count
+func (f *TableFormatter) Format(node parser.Node, _ *config.Config) error {
+	table, ok := node.(*parser.Table)
+	if !ok {
+		return nil
+	}
+
+	columns := len(table.Alignments)
+	if table.Header != nil {
+		f.formatRow(table.Header, table.Alignments, columns)
+	}
+	for _, row := range table.Rows {
+		f.formatRow(row, table.Alignments, columns)
+	}
+
+	return nil
+}
+
+// formatRow trims cell text, applies column alignments and pads or truncates
+// the row to the number of columns declared by the delimiter row
+func (f *TableFormatter) formatRow(row *parser.TableRow, alignments []parser.Alignment, columns int) {
+	for len(row.Cells) < columns {
+		row.Cells = append(row.Cells, &parser.TableCell{})
+	}
+	if columns > 0 && len(row.Cells) > columns {
+		row.Cells = row.Cells[:columns]
+	}
+
+	for i, cell := range row.Cells {
+		cell.Inlines = normalizeInlines(cell.Inlines)
+		if i < len(alignments) {
+			cell.Alignment = alignments[i]
+		}
+	}
+}
+
+// ThematicBreakFormatter formats thematic break nodes
+type ThematicBreakFormatter struct {
+	BaseFormatter
+}
+
+// NewThematicBreakFormatter creates a new thematic break formatter
+func NewThematicBreakFormatter() *ThematicBreakFormatter {
+	return &ThematicBreakFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "thematic_break",
+			priority: ThematicBreakFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true if this formatter can handle thematic breaks
+func (f *ThematicBreakFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeThematicBreak
+}
+
+// Format applies the configured canonical thematic break marker
+func (f *ThematicBreakFormatter) Format(node parser.Node, cfg *config.Config) error {
+	thematicBreak, ok := node.(*parser.ThematicBreak)
+	if !ok {
+		return nil
+	}
+
+	marker := cfg.ThematicBreak.Style
+	if marker == "" {
+		marker = config.DefaultThematicBreakStyle
+	}
+	if cfg.ThematicBreak.Length >= config.MinThematicBreakLength {
+		marker = strings.Repeat(marker[:1], cfg.ThematicBreak.Length)
+	}
+
+	thematicBreak.Marker = marker
+	return nil
+}
+
 // WhitespaceFormatter handles whitespace normalization
 type WhitespaceFormatter struct {
 	BaseFormatter
@@ -404,12 +756,12 @@ func (f *WhitespaceFormatter) Format(node parser.Node, cfg *config.Config) error
 	case *parser.Paragraph:
 		// Normalize paragraph whitespace
 		if cfg.Whitespace.TrimTrailingSpaces {
-			n.Text = f.trimTrailingSpaces(n.Text)
+			n.Inlines = trimInlines(n.Inlines)
 		}
 	case *parser.Heading:
 		// Normalize heading whitespace
 		if cfg.Whitespace.TrimTrailingSpaces {
-			n.Text = strings.TrimSpace(n.Text)
+			n.Inlines = trimInlines(n.Inlines)
 		}
 	case *parser.CodeBlock:
 		// For code blocks, be more careful with whitespace
@@ -461,76 +813,131 @@ func NewInlineFormatter() *InlineFormatter {
 	}
 }
 
-// CanFormat returns true if this formatter can handle text nodes (where inline elements are)
+// CanFormat returns true for the inline nodes this formatter normalizes
 func (f *InlineFormatter) CanFormat(nodeType parser.NodeType) bool {
-	return nodeType == parser.NodeText || nodeType == parser.NodeParagraph
+	switch nodeType {
+	case parser.NodeDocument, parser.NodeText, parser.NodeEmphasis, parser.NodeStrong, parser.NodeCodeSpan,
+		parser.NodeLink, parser.NodeImage, parser.NodeHardBreak:
+		return true
+	}
+	return false
 }
 
 // Format applies inline formatting rules
-func (f *InlineFormatter) Format(node parser.Node, _ *config.Config) error {
-	var text string
-
+func (f *InlineFormatter) Format(node parser.Node, cfg *config.Config) error {
 	switch n := node.(type) {
-	case *parser.Text:
-		text = n.Content
-		text = f.normalizeInlineElements(text)
-		n.Content = text
-	case *parser.Paragraph:
-		text = n.Text
-		text = f.normalizeInlineElements(text)
-		n.Text = text
-	default:
-		return nil
+	case *parser.HardBreak:
+		f.normalizeHardBreak(n, cfg.Whitespace.HardBreak)
+	case *parser.Document:
+		f.normalizeEmphasis(n)
+	case *parser.Link:
+		// Remove extra spaces in link text
+		n.Children = trimInlines(n.Children)
+	case *parser.Image:
+		n.Children = trimInlines(n.Children)
 	}
 
+	// Strong emphasis, code spans and text are left as written
 	return nil
 }
 
-// normalizeInlineElements cleans up inline markdown formatting
-func (f *InlineFormatter) normalizeInlineElements(text string) string {
-	// Normalize inline code backticks (ensure single backticks for simple inline code)
-	text = f.normalizeInlineCode(text)
-
-	// Normalize emphasis and strong formatting
-	text = f.normalizeEmphasis(text)
-
-	// Clean up link formatting
-	text = f.normalizeLinks(text)
-
-	return text
+// normalizeHardBreak writes a hard line break in the configured style
+func (f *InlineFormatter) normalizeHardBreak(lineBreak *parser.HardBreak, style string) {
+	switch style {
+	case config.HardBreakBackslash:
+		lineBreak.Marker = BackslashHardBreak
+	case config.HardBreakSpaces:
+		lineBreak.Marker = SpaceHardBreak
+	}
 }
 
-// normalizeInlineCode ensures consistent inline code formatting
-func (f *InlineFormatter) normalizeInlineCode(text string) string {
-	// Replace multiple backticks with single backticks where appropriate
-	// This is a simplified implementation
+// normalizeEmphasis ensures consistent emphasis formatting by converting
+// _text_ to *text* throughout the document. The emphasis is visited with its
+// parent and siblings, since whether asterisks are safe depends on them.
+func (f *InlineFormatter) normalizeEmphasis(doc *parser.Document) {
+	parser.Visit(doc, func(c *parser.Cursor) bool {
+		emphasis, ok := c.Node().(*parser.Emphasis)
+		if ok && emphasis.Marker == UnderscoreEmphasis && asterisksFit(emphasis, c.Parent(), c.Index()) {
+			emphasis.Marker = AsteriskEmphasis
+		}
+		return true
+	}, nil)
+}
+
+// asterisksFit reports whether emphasis at index among the inlines of parent
+// can be delimited with asterisks without reading differently. They would
+// pair up with or merge into other asterisks in the emphasized text, in an
+// enclosing emphasis or right next to the emphasis, and with nested emphasis
+// at either end.
+func asterisksFit(emphasis *parser.Emphasis, parent parser.Node, index int) bool {
+	if children := emphasis.Children; len(children) > 0 {
+		for _, edge := range []parser.Node{children[0], children[len(children)-1]} {
+			if edge.Type() == parser.NodeEmphasis || edge.Type() == parser.NodeStrong {
+				return false
+			}
+		}
+	}
 
-	// Remove spaces around inline code
-	re := regexp.MustCompile("`\\s+([^`]+)\\s+`")
-	text = re.ReplaceAllString(text, "`$1`")
+	if strings.Contains(emphasisMarker(parent), AsteriskEmphasis) {
+		return false
+	}
+	siblings := parser.Children(parent)
+	if index > 0 && touchesAsterisk(siblings[index-1], false) {
+		return false
+	}
+	if index+1 < len(siblings) && touchesAsterisk(siblings[index+1], true) {
+		return false
+	}
 
-	return text
+	fits := true
+	walkNodes(emphasis.Children, func(node parser.Node) {
+		if text, ok := node.(*parser.Text); ok {
+			for i := range text.Content {
+				if unescapedAsterisk(text.Content, i) {
+					fits = false
+				}
+			}
+		} else if strings.Contains(emphasisMarker(node), AsteriskEmphasis) {
+			fits = false
+		}
+	})
+	return fits
 }
 
-// normalizeEmphasis ensures consistent emphasis formatting
-func (f *InlineFormatter) normalizeEmphasis(text string) string {
-	// Normalize emphasis to use asterisks consistently
-	// Convert _text_ to *text*
-	re := regexp.MustCompile(`\b_([^_]+)_\b`)
-	text = re.ReplaceAllString(text, "*$1*")
-
-	// Normalize strong emphasis **text** (keep as is, it's already correct)
-	return text
+// touchesAsterisk reports whether a neighbour of an emphasis puts an asterisk
+// against it: at the start of node when it follows the emphasis, or at its
+// end when it comes before
+func touchesAsterisk(node parser.Node, follows bool) bool {
+	if text, ok := node.(*parser.Text); ok && text.Content != "" {
+		if follows {
+			return unescapedAsterisk(text.Content, 0)
+		}
+		return unescapedAsterisk(text.Content, len(text.Content)-1)
+	}
+	return strings.Contains(emphasisMarker(node), AsteriskEmphasis)
 }
 
-// normalizeLinks cleans up link formatting
-func (f *InlineFormatter) normalizeLinks(text string) string {
-	// Ensure proper spacing around links
-	// This is a basic implementation - could be enhanced
-
-	// Remove extra spaces in link text
-	re := regexp.MustCompile(`\[\s+([^\]]+)\s+\]`)
-	text = re.ReplaceAllString(text, "[$1]")
+// emphasisMarker returns the delimiter of emphasis and strong emphasis, or ""
+// for other nodes
+func emphasisMarker(node parser.Node) string {
+	switch n := node.(type) {
+	case *parser.Emphasis:
+		return n.Marker
+	case *parser.Strong:
+		return n.Marker
+	}
+	return ""
+}
 
-	return text
+// unescapedAsterisk reports whether s has an asterisk at i that is not
+// escaped by a backslash
+func unescapedAsterisk(s string, i int) bool {
+	if s[i] != '*' {
+		return false
+	}
+	backslashes := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 0
 }
@@ -0,0 +1,305 @@
+package formatter
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// toHTML converts GFM to HTML, to check that formatting did not change what
+// a document reads as
+func toHTML(t *testing.T, markdown string) string {
+	t.Helper()
+
+	var html bytes.Buffer
+	if err := goldmark.New(goldmark.WithExtensions(extension.GFM)).Convert([]byte(markdown), &html); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	return html.String()
+}
+
+func TestHeadingFormatter_ExplicitIDs(t *testing.T) {
+	cfg := config.Default()
+	cfg.Markdown.Dialect = config.Dialect{Preset: config.DialectExtended}
+	cfg.Heading.ExplicitIDs = true
+
+	content := `## Café & Things!
+
+## Intro {#intro}
+
+## Intro
+
+## Copyright &copy; 2024
+
+## Logo ![alt](x.png) here
+`
+	expected := `## Café & Things! {#café--things}
+
+## Intro {#intro}
+
+## Intro {#intro-1}
+
+## Copyright &copy; 2024 {#copyright--2024}
+
+## Logo ![alt](x.png) here {#logo--here}
+`
+
+	got := format(t, cfg, content)
+	if got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+	// The pinned IDs are read back as written
+	if again := format(t, cfg, got); again != got {
+		t.Errorf("Expected the output to be stable, got:\n%s", again)
+	}
+}
+
+func TestHeadingFormatter_StyleDiagnostics(t *testing.T) {
+	cfg := config.Default()
+	cfg.Heading.Style = config.HeadingStyleConsistent
+
+	// Inline HTML adds nothing to the text but leaves the spaces around it
+	content := "# First\n\n<img src=\"x.png\"> Spaced  <br>\n===\n\n## Same style\n"
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	diagnostics := NewHeadingFormatter().Check(doc, cfg)
+	expected := `heading "Spaced" uses setext style, but the first heading uses atx`
+	if len(diagnostics) != 1 || diagnostics[0].Message != expected || diagnostics[0].Pos.Line != 3 {
+		t.Errorf("Expected %q on line 3, got %v", expected, diagnostics)
+	}
+}
+
+func TestInlineFormatter_HardBreakStyle(t *testing.T) {
+	content := "Line one  \nLine two\\\nLine three\n\n- Item one  \n  item two\n"
+
+	tests := []struct {
+		style    string
+		expected string
+	}{
+		{config.HardBreakPreserve, "Line one  \nLine two\\\nLine three\n\n- Item one  \n  item two\n"},
+		{config.HardBreakBackslash, "Line one\\\nLine two\\\nLine three\n\n- Item one\\\n  item two\n"},
+		{config.HardBreakSpaces, "Line one  \nLine two  \nLine three\n\n- Item one  \n  item two\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Whitespace.HardBreak = tt.style
+
+			if got := format(t, cfg, content); got != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, got)
+			}
+		})
+	}
+
+	// A break written as spaces stays at the end of its line when text is wrapped
+	cfg := config.Default()
+	cfg.LineWidth = 12
+	content = "Alpha beta gamma delta  \nepsilon zeta\n"
+	expected := "Alpha beta\ngamma\ndelta  \nepsilon zeta\n"
+	if got := format(t, cfg, content); got != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, got)
+	}
+}
+
+func TestInlineFormatter_Emphasis(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"underscores", "_foo_ and _bar baz_\n", "*foo* and *bar baz*\n"},
+		{"asterisk inside", "_a*b_\n", "_a*b_\n"},
+		{"only an asterisk", "foo _*_\n", "foo _*_\n"},
+		{"escaped asterisk inside", "_a \\* b_\n", "*a \\* b*\n"},
+		{"inside asterisk emphasis", "*_foo_*\n", "*_foo_*\n"},
+		{"inside underscore emphasis", "_a _b_ c_\n", "*a _b_ c*\n"},
+		{"strong inside", "_a **b** c_\n", "_a **b** c_\n"},
+		{"next to asterisk emphasis", "_a_*b*\n", "_a_*b*\n"},
+		{"intraword underscores", "_a__b_\n", "*a__b*\n"},
+		{"after an asterisk", "x*_a_\n", "x*_a_\n"},
+		{"before an asterisk", "_a_*x\n", "_a_*x\n"},
+		{"code span with asterisk", "_a `*` b_\n", "*a `*` b*\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := format(t, config.Default(), tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, got)
+			}
+			if want, html := toHTML(t, tt.content), toHTML(t, got); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestInlineFormatter_CodeSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"leading space", "` a`\n", "` a`\n"},
+		{"no-break spaces", "`\u00a0a\u00a0`\n", "`\u00a0a\u00a0`\n"},
+		{"stripped spaces", "` a `\n", "`a`\n"},
+		{"padded backticks", "` `` `\n", "``` `` ```\n"},
+		{"trailing space", "``foo ``\n", "`foo `\n"},
+		{"spaces only", "`  `\n", "`  `\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := format(t, config.Default(), tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, got)
+			}
+			if want, html := toHTML(t, tt.content), toHTML(t, got); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestListFormatter_ConsecutiveLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		bullet   string
+		number   string
+		content  string
+		expected string
+	}{
+		{"bullets", "-", ".", "- a\n+ b\n", "- a\n\n* b\n"},
+		{"three bullets", "-", ".", "* a\n- b\n+ c\n", "- a\n\n* b\n\n- c\n"},
+		{"asterisk bullets", "*", ".", "- a\n+ b\n", "* a\n\n- b\n"},
+		{"delimiters", "-", ".", "1. a\n3) b\n", "1. a\n\n3) b\n"},
+		{"parenthesis delimiters", "-", ")", "1. a\n3) b\n", "1) a\n\n3. b\n"},
+		{"different kinds", "-", ".", "- a\n1. b\n", "- a\n\n1. b\n"},
+		{"nested", "-", ".", "- a\n  - b\n  + c\n", "- a\n  - b\n  * c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.List.BulletStyle = tt.bullet
+			cfg.List.NumberStyle = tt.number
+
+			got := format(t, cfg, tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if want, html := toHTML(t, tt.content), toHTML(t, got); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+			if again := format(t, cfg, got); again != got {
+				t.Errorf("Expected the output to be stable, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestListFormatter_OrderedNumbering(t *testing.T) {
+	content := "1. a\n1. b\n5. c\n\n---\n\n3) x\n7) y\n   1. nested\n   1. again\n"
+
+	tests := []struct {
+		numbering string
+		expected  string
+	}{
+		{
+			numbering: config.OrderedNumberingIncremental,
+			expected:  "1. a\n2. b\n3. c\n\n---\n\n3. x\n4. y\n   1. nested\n   2. again\n",
+		},
+		{
+			numbering: config.OrderedNumberingAllOnes,
+			expected:  "1. a\n1. b\n1. c\n\n---\n\n3. x\n3. y\n   1. nested\n   1. again\n",
+		},
+		{
+			numbering: config.OrderedNumberingPreserve,
+			expected:  "1. a\n1. b\n5. c\n\n---\n\n3. x\n7. y\n   1. nested\n   1. again\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.numbering, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.List.OrderedNumbering = tt.numbering
+
+			got := format(t, cfg, content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if want, html := toHTML(t, content), toHTML(t, got); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestEngine_HTMLBlocksVerbatim(t *testing.T) {
+	cfg := config.Default()
+	cfg.LineWidth = 20
+
+	// Formatters skip HTML blocks, so trailing spaces, emphasis markers, tabs,
+	// long lines and runs of blank lines all come back byte for byte
+	content := "   <div   class=\"odd\">\n" +
+		"  *not*   __emphasis__ and a line much longer than the line width   \n" +
+		"\t<span>tab</span>\n" +
+		"</div>\n\n" +
+		"<custom-element>\n" +
+		"   - not a list\n" +
+		"  1) nor this  \n\n" +
+		"A paragraph.\n\n" +
+		"<pre>\n" +
+		"  keep   this\n\n\n\n" +
+		"     *indented*\n" +
+		"</pre>\n\n" +
+		"<!-- a comment\n\n\nwith blank lines   -->\n"
+
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if blocks := parser.FindNodes(doc, parser.NodeHTMLBlock); len(blocks) != 4 {
+		t.Fatalf("Expected 4 HTML blocks, got %v", blocks)
+	}
+
+	engine := New()
+	recorder := &recordingFormatter{BaseFormatter: BaseFormatter{name: "recording", priority: math.MaxInt}}
+	engine.Register(recorder)
+	if err := engine.Format(doc, cfg); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if recorder.formatted[parser.NodeHTMLBlock] > 0 || recorder.formatted[parser.NodeParagraph] != 1 {
+		t.Errorf("Expected only the paragraph to be formatted, got %v", recorder.formatted)
+	}
+
+	if got := format(t, cfg, content); got != content {
+		t.Errorf("Expected:\n%q\nGot:\n%q", content, got)
+	}
+}
+
+// recordingFormatter counts the nodes of each type it is asked to format
+type recordingFormatter struct {
+	BaseFormatter
+	formatted map[parser.NodeType]int
+}
+
+func (f *recordingFormatter) CanFormat(parser.NodeType) bool { return true }
+
+func (f *recordingFormatter) Format(node parser.Node, _ *config.Config) error {
+	if f.formatted == nil {
+		f.formatted = make(map[parser.NodeType]int)
+	}
+	f.formatted[node.Type()]++
+	return nil
+}
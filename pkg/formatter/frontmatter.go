@@ -0,0 +1,114 @@
+package formatter
+
+import (
+	"bytes"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// FrontMatterFormatter optionally re-serializes YAML front matter
+type FrontMatterFormatter struct {
+	BaseFormatter
+}
+
+// NewFrontMatterFormatter creates a new front matter formatter
+func NewFrontMatterFormatter() *FrontMatterFormatter {
+	return &FrontMatterFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "front_matter",
+			priority: FrontMatterFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true if this formatter can handle front matter
+func (f *FrontMatterFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeFrontMatter
+}
+
+// Format re-serializes YAML front matter when normalization is enabled.
+// TOML front matter and YAML that fails to parse are left exactly as written.
+func (f *FrontMatterFormatter) Format(node parser.Node, cfg *config.Config) error {
+	frontMatter, ok := node.(*parser.FrontMatter)
+	if !ok || !cfg.FrontMatter.Normalize || frontMatter.Format != parser.FrontMatterYAML {
+		return nil
+	}
+
+	normalized, err := normalizeYAML(frontMatter.Content, &cfg.FrontMatter)
+	if err != nil {
+		return nil
+	}
+
+	frontMatter.Content = normalized
+	return nil
+}
+
+// normalizeYAML decodes YAML into a node tree, which keeps key order and
+// comments, and encodes it again with the configured indentation
+func normalizeYAML(content string, cfg *config.FrontMatterConfig) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", err
+	}
+	if doc.Kind == 0 {
+		// Empty front matter
+		return content, nil
+	}
+
+	if cfg.SortKeys {
+		sortYAMLKeys(&doc)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(cfg.Indent)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// sortYAMLKeys recursively sorts the keys of every mapping in the tree. Head
+// and line comments belong to a key or value and move with it. yaml.v3 gives
+// some comments between two entries to the entry before them as a foot
+// comment though, so mappings with foot comments keep their order rather
+// than move those comments onto another key.
+func sortYAMLKeys(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode && !hasFootComments(node) {
+		pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i][0].Value < pairs[j][0].Value
+		})
+
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair[0], pair[1])
+		}
+	}
+
+	for _, child := range node.Content {
+		sortYAMLKeys(child)
+	}
+}
+
+// hasFootComments reports whether any key or value of a mapping has a foot comment
+func hasFootComments(mapping *yaml.Node) bool {
+	for _, child := range mapping.Content {
+		if child.FootComment != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestFrontMatterFormatter_SortKeysWithComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected string
+	}{
+		{
+			name:     "commented keys",
+			yaml:     "title: Hello\n# Shown in the sidebar\nsidebar: true # until 2.0\n# Sort order\norder: 1\n",
+			expected: "# Sort order\norder: 1\n# Shown in the sidebar\nsidebar: true # until 2.0\ntitle: Hello\n",
+		},
+		{
+			name:     "nested mapping",
+			yaml:     "tags:\n    # Primary\n    b: 1\n    a: 2 # secondary\nauthor: me\n",
+			expected: "author: me\ntags:\n  a: 2 # secondary\n  # Primary\n  b: 1\n",
+		},
+		{
+			name:     "comment before a blank line",
+			yaml:     "title: Hello\n# Drafts are not published\n\ndraft: true\n",
+			expected: "title: Hello\n# Drafts are not published\n\ndraft: true\n",
+		},
+		{
+			name:     "comment after a nested mapping",
+			yaml:     "params:\n  b: 1\n  a: 2\n  # More params go here\ndraft: true\n",
+			expected: "draft: true\nparams:\n  b: 1\n  a: 2\n  # More params go here\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.FrontMatter.Normalize = true
+			cfg.FrontMatter.SortKeys = true
+			cfg.FrontMatter.Indent = 2
+
+			content := "---\n" + tt.yaml + "---\n\n# Page\n"
+			expected := "---\n" + tt.expected + "---\n\n# Page\n"
+			if got := format(t, cfg, content); got != expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+			}
+		})
+	}
+}
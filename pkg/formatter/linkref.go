@@ -0,0 +1,233 @@
+package formatter
+
+import (
+	"sort"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// LinkReferenceFormatter sorts, deduplicates, prunes and moves link reference definitions
+type LinkReferenceFormatter struct {
+	BaseFormatter
+}
+
+// NewLinkReferenceFormatter creates a new link reference definition formatter
+func NewLinkReferenceFormatter() *LinkReferenceFormatter {
+	return &LinkReferenceFormatter{
+		BaseFormatter: BaseFormatter{
+			name:     "link-reference",
+			priority: LinkReferenceFormatterPriority,
+		},
+	}
+}
+
+// CanFormat returns true for documents, since definitions apply to the whole document
+func (f *LinkReferenceFormatter) CanFormat(nodeType parser.NodeType) bool {
+	return nodeType == parser.NodeDocument
+}
+
+// Format applies the configured link reference definition rules
+func (f *LinkReferenceFormatter) Format(node parser.Node, cfg *config.Config) error {
+	doc, ok := node.(*parser.Document)
+	if !ok {
+		return nil
+	}
+
+	definitions := linkReferenceDefinitions(doc)
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	drop := make(map[*parser.LinkReferenceDefinition]bool)
+	if cfg.LinkReferences.Deduplicate {
+		// Only the first definition of a label is used, so later ones can go
+		seen := make(map[string]bool)
+		for _, definition := range definitions {
+			label := definition.NormalizedLabel()
+			if seen[label] {
+				drop[definition] = true
+			}
+			seen[label] = true
+		}
+	}
+
+	if cfg.LinkReferences.RemoveUnused {
+		used := referencedLinkLabels(doc.Children)
+		for _, definition := range definitions {
+			if !used[definition.NormalizedLabel()] {
+				drop[definition] = true
+			}
+		}
+	}
+
+	kept := make([]*parser.LinkReferenceDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		if !drop[definition] {
+			kept = append(kept, definition)
+		}
+	}
+
+	switch cfg.LinkReferences.Placement {
+	case config.LinkReferencePlacementDocument:
+		removeLinkReferenceDefinitions(doc, nil)
+		doc.Children = appendLinkReferenceDefinitions(doc.Children, kept, cfg.LinkReferences.Sort)
+	case config.LinkReferencePlacementSection:
+		placeInSections(doc, kept, cfg.LinkReferences.Sort)
+	default:
+		removeLinkReferenceDefinitions(doc, drop)
+		if cfg.LinkReferences.Sort {
+			sortLinkReferenceRuns(doc)
+		}
+	}
+
+	return nil
+}
+
+// placeInSections moves every definition to the end of the section that
+// first refers to it, or the section it is defined in if it is unused.
+// Sections are delimited by top-level headings.
+func placeInSections(doc *parser.Document, definitions []*parser.LinkReferenceDefinition, sorted bool) {
+	definedIn := make(map[*parser.LinkReferenceDefinition]int)
+	firstUse := make(map[string]int)
+
+	section := 0
+	for _, child := range doc.Children {
+		if child.Type() == parser.NodeHeading {
+			section++
+		}
+		for _, definition := range linkReferenceDefinitions(child) {
+			definedIn[definition] = section
+		}
+		for label := range referencedLinkLabels([]parser.Node{child}) {
+			if _, seen := firstUse[label]; !seen {
+				firstUse[label] = section
+			}
+		}
+	}
+
+	sections := make(map[int][]*parser.LinkReferenceDefinition)
+	for _, definition := range definitions {
+		target, used := firstUse[definition.NormalizedLabel()]
+		if !used {
+			target = definedIn[definition]
+		}
+		sections[target] = append(sections[target], definition)
+	}
+
+	removeLinkReferenceDefinitions(doc, nil)
+	children := make([]parser.Node, 0, len(doc.Children)+len(definitions))
+
+	section = 0
+	for _, child := range doc.Children {
+		if child.Type() == parser.NodeHeading {
+			children = appendLinkReferenceDefinitions(children, sections[section], sorted)
+			section++
+		}
+		children = append(children, child)
+	}
+	doc.Children = appendLinkReferenceDefinitions(children, sections[section], sorted)
+}
+
+// appendLinkReferenceDefinitions appends definitions to nodes, sorted by label if requested
+func appendLinkReferenceDefinitions(nodes []parser.Node, definitions []*parser.LinkReferenceDefinition, sorted bool) []parser.Node {
+	if sorted {
+		sortLinkReferenceDefinitions(definitions)
+	}
+	for _, definition := range definitions {
+		nodes = append(nodes, definition)
+	}
+	return nodes
+}
+
+// sortLinkReferenceDefinitions sorts definitions by their normalized label
+func sortLinkReferenceDefinitions(definitions []*parser.LinkReferenceDefinition) {
+	sort.SliceStable(definitions, func(i, j int) bool {
+		return definitions[i].NormalizedLabel() < definitions[j].NormalizedLabel()
+	})
+}
+
+// sortLinkReferenceRuns sorts each run of consecutive definitions in place,
+// wherever it is nested
+func sortLinkReferenceRuns(root parser.Node) {
+	var runs [][]*parser.LinkReferenceDefinition
+	var parent parser.Node
+	index := 0
+	parser.Visit(root, func(c *parser.Cursor) bool {
+		definition, ok := c.Node().(*parser.LinkReferenceDefinition)
+		if !ok {
+			return true
+		}
+		if len(runs) == 0 || c.Parent() != parent || c.Index() != index+1 {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], definition)
+		parent, index = c.Parent(), c.Index()
+		return false
+	}, nil)
+
+	// Every definition is replaced by the one that takes its place in the sorted run
+	sorted := make(map[*parser.LinkReferenceDefinition]*parser.LinkReferenceDefinition)
+	for _, run := range runs {
+		order := append([]*parser.LinkReferenceDefinition{}, run...)
+		sortLinkReferenceDefinitions(order)
+		for i, definition := range run {
+			sorted[definition] = order[i]
+		}
+	}
+	parser.Visit(root, func(c *parser.Cursor) bool {
+		definition, ok := c.Node().(*parser.LinkReferenceDefinition)
+		if ok {
+			c.Replace(sorted[definition])
+		}
+		return !ok
+	}, nil)
+}
+
+// linkReferenceDefinitions returns all link reference definitions in the
+// tree rooted at root in document order
+func linkReferenceDefinitions(root parser.Node) []*parser.LinkReferenceDefinition {
+	var definitions []*parser.LinkReferenceDefinition
+	parser.Inspect(root, func(node parser.Node) bool {
+		if definition, ok := node.(*parser.LinkReferenceDefinition); ok {
+			definitions = append(definitions, definition)
+		}
+		return true
+	})
+	return definitions
+}
+
+// removeLinkReferenceDefinitions removes the given definitions, or all of
+// them if remove is nil, from the tree rooted at root. Blockquotes left
+// empty are removed as well.
+func removeLinkReferenceDefinitions(root parser.Node, remove map[*parser.LinkReferenceDefinition]bool) {
+	removeNodes(root, func(node parser.Node) bool {
+		definition, ok := node.(*parser.LinkReferenceDefinition)
+		return ok && (remove == nil || remove[definition])
+	})
+}
+
+// referencedLinkLabels returns the normalized labels of all reference links
+// and images among the nodes and their descendants
+func referencedLinkLabels(nodes []parser.Node) map[string]bool {
+	labels := make(map[string]bool)
+	use := func(reference parser.ReferenceStyle, label string, children []parser.Node) {
+		if reference == parser.ReferenceInline {
+			return
+		}
+		if label == "" {
+			label = parser.PlainText(children)
+		}
+		labels[parser.NormalizeLinkLabel(label)] = true
+	}
+
+	walkNodes(nodes, func(node parser.Node) {
+		switch n := node.(type) {
+		case *parser.Link:
+			use(n.Reference, n.Label, n.Children)
+		case *parser.Image:
+			use(n.Reference, n.Label, n.Children)
+		}
+	})
+	return labels
+}
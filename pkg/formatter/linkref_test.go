@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+)
+
+func TestLinkReferenceFormatter_NestedDefinitions(t *testing.T) {
+	content := `See [b], [a] and [q].
+
+- item
+
+  [q]: http://q
+
+> [b]: http://b
+> [a]: http://a
+`
+
+	tests := []struct {
+		name      string
+		placement string
+		expected  string
+	}{
+		{
+			name:      "sorted in place",
+			placement: config.LinkReferencePlacementPreserve,
+			expected: `See [b], [a] and [q].
+
+- item
+
+  [q]: http://q
+
+> [a]: http://a
+> [b]: http://b
+`,
+		},
+		{
+			name:      "moved to the document end",
+			placement: config.LinkReferencePlacementDocument,
+			expected: `See [b], [a] and [q].
+
+- item
+
+[a]: http://a
+[b]: http://b
+[q]: http://q
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.LinkReferences.Sort = true
+			cfg.LinkReferences.Placement = tt.placement
+
+			if got := format(t, cfg, content); got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
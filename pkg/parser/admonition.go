@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// admonitionParserPriority places the MkDocs admonition parser right
+	// before goldmark's thematic break parser
+	admonitionParserPriority = 150
+	// admonitionIndent is the indentation of the body of a MkDocs admonition
+	admonitionIndent = 4
+)
+
+var (
+	// kindAdmonition is the goldmark node kind of MkDocs admonitions
+	kindAdmonition = ast.NewNodeKind("Admonition")
+
+	// mkdocsAdmonitionStart matches the first line of a MkDocs admonition:
+	// the marker, the kind with optional further classes and an optional
+	// quoted title, as accepted by Python-Markdown and pymdownx.details
+	mkdocsAdmonitionStart = regexp.MustCompile(`^(!!!|\?\?\?\+?) ?([\w-]+(?: +[\w-]+)*)(?: +"(.*)")? *$`)
+	// githubAlertStart matches the first line of a GitHub alert inside its
+	// blockquote. GitHub only renders the alert when the marker is alone on
+	// its line, so text after it makes the blockquote an ordinary one.
+	githubAlertStart = regexp.MustCompile(`^\[!([\w-]+)\][ \t]*$`)
+
+	// githubAlertKinds are the alert kinds GitHub renders
+	githubAlertKinds = []string{"NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION"}
+	// mkdocsAdmonitionKinds are the admonition types styled by MkDocs
+	// Material, including their aliases
+	mkdocsAdmonitionKinds = []string{
+		"note", "abstract", "summary", "tldr", "info", "todo", "tip", "hint", "important",
+		"success", "check", "done", "question", "help", "faq", "warning", "caution", "attention",
+		"failure", "fail", "missing", "danger", "error", "bug", "example", "quote", "cite",
+	}
+)
+
+// IsKnownAdmonitionKind reports whether kind is one of the kinds the given
+// syntax defines. Case is ignored, and for MkDocs only the first class is
+// looked at.
+func IsKnownAdmonitionKind(syntax, kind string) bool {
+	switch syntax {
+	case AdmonitionSyntaxGitHub:
+		return containsFold(githubAlertKinds, kind)
+	case AdmonitionSyntaxMkDocs:
+		first, _, _ := strings.Cut(kind, " ")
+		return containsFold(mkdocsAdmonitionKinds, first)
+	}
+	return false
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// admonitionExtension parses MkDocs admonitions. GitHub alerts are ordinary
+// blockquotes to goldmark and are recognized when they are converted.
+var admonitionExtension goldmark.Extender = &admonitions{}
+
+type admonitions struct{}
+
+// Extend registers the admonition block parser
+func (e *admonitions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(
+			util.Prioritized(admonitionParser{}, admonitionParserPriority),
+		),
+	)
+}
+
+// admonitionNode is a MkDocs admonition in the goldmark AST. Its only line
+// is the marker line; the body is parsed into its children.
+type admonitionNode struct {
+	ast.BaseBlock
+	marker string
+	kind   string
+	title  string
+	titled bool
+}
+
+// Kind returns the kind of admonition nodes
+func (n *admonitionNode) Kind() ast.NodeKind { return kindAdmonition }
+
+// IsRaw reports that the marker line is not parsed as inline content
+func (n *admonitionNode) IsRaw() bool { return true }
+
+// Dump dumps the node for debugging
+func (n *admonitionNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.kind, "Title": n.title}, nil)
+}
+
+// admonitionParser parses MkDocs admonitions: a marker line such as
+// !!! note "Title" followed by blocks indented by four spaces. Blank lines
+// do not end the body, only a line with less indentation does.
+type admonitionParser struct{}
+
+// Trigger returns the characters that open admonitions
+func (b admonitionParser) Trigger() []byte {
+	return []byte{'!', '?'}
+}
+
+// Open starts an admonition at its marker line
+func (b admonitionParser) Open(_ ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, gmparser.NoChildren
+	}
+	match := mkdocsAdmonitionStart.FindSubmatch(util.TrimRightSpace(line[pos:]))
+	if match == nil {
+		return nil, gmparser.NoChildren
+	}
+
+	node := &admonitionNode{
+		marker: string(match[1]),
+		kind:   string(match[2]),
+		title:  string(match[3]),
+		titled: match[3] != nil,
+	}
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+	reader.AdvanceToEOL()
+	return node, gmparser.HasChildren
+}
+
+// Continue keeps indented and blank lines in the admonition body
+func (b admonitionParser) Continue(_ ast.Node, reader text.Reader, _ gmparser.Context) gmparser.State {
+	line, _ := reader.PeekLine()
+	if util.IsBlank(line) {
+		reader.AdvanceToEOL()
+		return gmparser.Continue | gmparser.HasChildren
+	}
+
+	indent, _ := util.IndentWidth(line, reader.LineOffset())
+	if indent < admonitionIndent {
+		return gmparser.Close
+	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), admonitionIndent)
+	reader.AdvanceAndSetPadding(pos, padding)
+	return gmparser.Continue | gmparser.HasChildren
+}
+
+// Close finishes an admonition
+func (b admonitionParser) Close(ast.Node, text.Reader, gmparser.Context) {}
+
+// CanInterruptParagraph reports that admonitions must start a new block,
+// as in Python-Markdown
+func (b admonitionParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine reports that admonitions cannot open with indented code indentation
+func (b admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// convertAdmonition converts a MkDocs admonition with its body
+func (p *GoldmarkParser) convertAdmonition(n ast.Node, source []byte) Node {
+	admonition := n.(*admonitionNode)
+	return &Admonition{
+		Syntax:    AdmonitionSyntaxMkDocs,
+		Marker:    admonition.marker,
+		Kind:      admonition.kind,
+		Title:     admonition.title,
+		HideTitle: admonition.titled && admonition.title == "",
+		Children:  p.convertChildren(n, source),
+	}
+}
+
+// githubAlert returns the blockquote n as a GitHub alert if its first line
+// is an alert marker such as [!NOTE], or nil. children are the converted
+// blocks of the blockquote; the marker line is taken out of the first one.
+func githubAlert(n ast.Node, children []Node, source []byte) *Admonition {
+	first := n.FirstChild()
+	if first == nil || first.Kind() != ast.KindParagraph || len(children) == 0 {
+		return nil
+	}
+	paragraph, ok := children[0].(*Paragraph)
+	if !ok || len(paragraph.Inlines) == 0 {
+		return nil
+	}
+	if text, ok := paragraph.Inlines[0].(*Text); !ok || !strings.HasPrefix(text.Content, "[!") {
+		return nil // the marker is a link to a defined label
+	}
+
+	lines := first.Lines()
+	marker := lines.At(0)
+	match := githubAlertStart.FindSubmatch(util.TrimRightSpace(marker.Value(source)))
+	if match == nil {
+		return nil
+	}
+
+	// The marker line must end with a line break of the paragraph, rather
+	// than inside an inline that spans lines
+	var breaks []int
+	for i, inline := range paragraph.Inlines {
+		if inline.Type() == NodeSoftBreak || inline.Type() == NodeHardBreak {
+			breaks = append(breaks, i)
+		}
+	}
+	if len(breaks) != lines.Len()-1 {
+		return nil
+	}
+
+	alert := &Admonition{
+		Syntax: AdmonitionSyntaxGitHub,
+		Kind:   string(match[1]),
+	}
+	if len(breaks) == 0 {
+		alert.Children = children[1:]
+		return alert
+	}
+	paragraph.Inlines = paragraph.Inlines[breaks[0]+1:]
+	paragraph.SetRange(Position{Offset: lines.At(1).Start}, paragraph.End())
+	alert.Children = children
+	return alert
+}
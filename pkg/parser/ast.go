@@ -24,52 +24,153 @@ const (
 	NodeCodeBlock
 	// NodeText represents plain text content
 	NodeText
+	// NodeBlockquote represents a blockquote (> quoted text) containing other blocks
+	NodeBlockquote
+	// NodeTable represents a GFM table
+	NodeTable
+	// NodeTableRow represents a single row of a table
+	NodeTableRow
+	// NodeTableCell represents a single cell of a table row
+	NodeTableCell
+	// NodeThematicBreak represents a thematic break (---, ***, ___)
+	NodeThematicBreak
+	// NodeHTMLBlock represents a raw HTML block, including HTML comments
+	NodeHTMLBlock
+	// NodeFrontMatter represents a YAML or TOML front matter block
+	NodeFrontMatter
+	// NodeFootnoteReference represents an inline footnote reference ([^label])
+	NodeFootnoteReference
+	// NodeFootnoteDefinition represents a footnote definition ([^label]: text)
+	NodeFootnoteDefinition
+	// NodeLinkReferenceDefinition represents a link reference definition ([label]: url)
+	NodeLinkReferenceDefinition
+	// NodeImage represents an inline image (![alt](src "title"))
+	NodeImage
+	// NodeEmphasis represents emphasized text (*text* or _text_)
+	NodeEmphasis
+	// NodeStrong represents strongly emphasized text (**text** or __text__)
+	NodeStrong
+	// NodeStrikethrough represents GFM strikethrough text (~~text~~)
+	NodeStrikethrough
+	// NodeCodeSpan represents inline code (`code`)
+	NodeCodeSpan
+	// NodeLink represents an inline or reference link ([text](url))
+	NodeLink
+	// NodeAutolink represents an autolink (<https://example.com>) or a bare URL
+	NodeAutolink
+	// NodeRawHTML represents inline HTML, such as a tag or a comment
+	NodeRawHTML
+	// NodeSoftBreak represents a line ending inside a paragraph
+	NodeSoftBreak
+	// NodeHardBreak represents a hard line break (backslash or two trailing spaces)
+	NodeHardBreak
+	// NodeMathBlock represents a display math block ($$ ... $$)
+	NodeMathBlock
+	// NodeInlineMath represents inline math ($x$ or $$x$$)
+	NodeInlineMath
+	// NodeAdmonition represents a GitHub alert (> [!NOTE]) or MkDocs admonition (!!! note)
+	NodeAdmonition
+	// NodeDefinitionList represents a definition list of terms and definitions
+	NodeDefinitionList
+	// NodeTerm represents a term of a definition list
+	NodeTerm
+	// NodeDefinition represents a definition (: text) of a definition list
+	NodeDefinition
+	// NodeESM represents MDX import and export statements
+	NodeESM
+	// NodeJSX represents an MDX JSX element or expression on lines of its own
+	NodeJSX
 )
 
 // Node represents a basic node in the markdown AST
 type Node interface {
 	Type() NodeType
 	String() string
+	// Pos returns the source position of the first byte of the node
+	Pos() Position
+	// End returns the source position immediately after the node
+	End() Position
 }
 
 // Document represents the root document node
 type Document struct {
-	Children []Node
+	Range
+	FrontMatter *FrontMatter // nil when the document has no front matter
+	Children    []Node
 }
 
 // Type returns the node type for Document nodes.
 func (n *Document) Type() NodeType { return NodeDocument }
 func (n *Document) String() string { return "Document" }
 
+// Heading styles, as written in the source
+const (
+	// HeadingStyleATX is a heading introduced by hashes (## Title)
+	HeadingStyleATX = "atx"
+	// HeadingStyleATXClosed is an ATX heading with closing hashes (## Title ##)
+	HeadingStyleATXClosed = "atx-closed"
+	// HeadingStyleSetext is a heading underlined with = or - (levels 1 and 2)
+	HeadingStyleSetext = "setext"
+)
+
 // Heading represents a heading node
 type Heading struct {
-	Level int
-	Text  string
-	Style string // "atx" or "setext"
+	Range
+	Level   int
+	Inlines []Node
+	Style   string // "atx", "atx-closed" or "setext"
+	// ID is the identifier set in the heading's attribute block ({#id})
+	ID string
+	// Classes are the classes set in the heading's attribute block ({.class})
+	Classes []string
+	// Attributes are the other key=value pairs of the attribute block, in order
+	Attributes []Attribute
+	// AutoID is the GitHub-compatible anchor generated from the heading text,
+	// set only when the heading has no ID of its own
+	AutoID string
+}
+
+// Attribute is a key=value pair of an attribute block. Values are kept as
+// text, whether they were written quoted or not.
+type Attribute struct {
+	Key   string
+	Value string
 }
 
 // Type returns the node type for Heading nodes.
 func (n *Heading) Type() NodeType { return NodeHeading }
 func (n *Heading) String() string {
-	return fmt.Sprintf("Heading(level=%d, text=%q)", n.Level, n.Text)
+	return fmt.Sprintf("Heading(level=%d, text=%q)", n.Level, n.Text())
 }
 
+// Text returns the plain text of the heading
+func (n *Heading) Text() string { return PlainText(n.Inlines) }
+
 // Paragraph represents a paragraph node
 type Paragraph struct {
-	Text string
+	Range
+	Inlines []Node
 }
 
 // Type returns the node type for Paragraph nodes.
 func (n *Paragraph) Type() NodeType { return NodeParagraph }
 func (n *Paragraph) String() string {
-	return fmt.Sprintf("Paragraph(text=%q)", n.Text)
+	return fmt.Sprintf("Paragraph(text=%q)", n.Text())
 }
 
+// Text returns the plain text of the paragraph
+func (n *Paragraph) Text() string { return PlainText(n.Inlines) }
+
 // List represents a list node
 type List struct {
+	Range
 	Ordered bool
-	Items   []*ListItem
-	Marker  string
+	// Start is the number of the first item of an ordered list
+	Start int
+	// Tight lists have no blank lines between or inside their items
+	Tight  bool
+	Items  []*ListItem
+	Marker string
 }
 
 // Type returns the node type for List nodes.
@@ -78,21 +179,51 @@ func (n *List) String() string {
 	return fmt.Sprintf("List(ordered=%t, items=%d)", n.Ordered, len(n.Items))
 }
 
+// TaskState is the checkbox state of a GFM task list item
+type TaskState int
+
+const (
+	// TaskNone means the item is not a task
+	TaskNone TaskState = iota
+	// TaskUnchecked means the item is an open task ([ ])
+	TaskUnchecked
+	// TaskChecked means the item is a completed task ([x])
+	TaskChecked
+)
+
+// String returns the name of the task state
+func (s TaskState) String() string {
+	switch s {
+	case TaskUnchecked:
+		return "unchecked"
+	case TaskChecked:
+		return "checked"
+	default:
+		return "none"
+	}
+}
+
 // ListItem represents a list item node
 type ListItem struct {
-	Text     string
+	Range
+	Inlines  []Node
 	Marker   string
-	Children []Node // Support for nested lists and other elements
+	Task     TaskState
+	Children []Node // Blocks after the item's text: paragraphs, code, quotes, nested lists...
 }
 
 // Type returns the node type for ListItem nodes.
 func (n *ListItem) Type() NodeType { return NodeListItem }
 func (n *ListItem) String() string {
-	return fmt.Sprintf("ListItem(text=%q)", n.Text)
+	return fmt.Sprintf("ListItem(text=%q, task=%s)", n.Text(), n.Task)
 }
 
+// Text returns the plain text of the item's own content, without nested blocks
+func (n *ListItem) Text() string { return PlainText(n.Inlines) }
+
 // CodeBlock represents a code block node
 type CodeBlock struct {
+	Range
 	Language string
 	Content  string
 	Fenced   bool
@@ -105,8 +236,10 @@ func (n *CodeBlock) String() string {
 	return fmt.Sprintf("CodeBlock(lang=%q, fenced=%t)", n.Language, n.Fenced)
 }
 
-// Text represents a text node
+// Text represents a text node. Inside inline content it holds a run of
+// literal text as written, including backslash escapes and entities.
 type Text struct {
+	Range
 	Content string
 }
 
@@ -116,15 +249,530 @@ func (n *Text) String() string {
 	return fmt.Sprintf("Text(content=%q)", n.Content)
 }
 
+// Blockquote represents a blockquote node whose children are arbitrary blocks
+type Blockquote struct {
+	Range
+	Children []Node
+}
+
+// Type returns the node type for Blockquote nodes.
+func (n *Blockquote) Type() NodeType { return NodeBlockquote }
+func (n *Blockquote) String() string {
+	return fmt.Sprintf("Blockquote(children=%d)", len(n.Children))
+}
+
+// Alignment represents the alignment of a table column
+type Alignment int
+
+const (
+	// AlignNone means the column has no explicit alignment
+	AlignNone Alignment = iota
+	// AlignLeft means the column is left aligned (:---)
+	AlignLeft
+	// AlignCenter means the column is centered (:---:)
+	AlignCenter
+	// AlignRight means the column is right aligned (---:)
+	AlignRight
+)
+
+// String returns the name of the alignment
+func (a Alignment) String() string {
+	switch a {
+	case AlignLeft:
+		return "left"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	default:
+		return "none"
+	}
+}
+
+// Table represents a GFM table with a header row and body rows
+type Table struct {
+	Range
+	Alignments []Alignment
+	Header     *TableRow
+	Rows       []*TableRow
+}
+
+// Type returns the node type for Table nodes.
+func (n *Table) Type() NodeType { return NodeTable }
+func (n *Table) String() string {
+	return fmt.Sprintf("Table(columns=%d, rows=%d)", len(n.Alignments), len(n.Rows))
+}
+
+// TableRow represents a single row of a table
+type TableRow struct {
+	Range
+	Cells []*TableCell
+}
+
+// Type returns the node type for TableRow nodes.
+func (n *TableRow) Type() NodeType { return NodeTableRow }
+func (n *TableRow) String() string {
+	return fmt.Sprintf("TableRow(cells=%d)", len(n.Cells))
+}
+
+// TableCell represents a single table cell with inline content
+type TableCell struct {
+	Range
+	Inlines   []Node
+	Alignment Alignment
+}
+
+// Type returns the node type for TableCell nodes.
+func (n *TableCell) Type() NodeType { return NodeTableCell }
+func (n *TableCell) String() string {
+	return fmt.Sprintf("TableCell(text=%q, align=%s)", n.Text(), n.Alignment)
+}
+
+// Text returns the plain text of the cell
+func (n *TableCell) Text() string { return PlainText(n.Inlines) }
+
+// ThematicBreak represents a thematic break (horizontal rule) node
+type ThematicBreak struct {
+	Range
+	Marker string // the exact marker to render, e.g. "---" or "***"
+}
+
+// Type returns the node type for ThematicBreak nodes.
+func (n *ThematicBreak) Type() NodeType { return NodeThematicBreak }
+func (n *ThematicBreak) String() string {
+	return fmt.Sprintf("ThematicBreak(marker=%q)", n.Marker)
+}
+
+// HTMLBlock represents a raw HTML block that is kept verbatim
+type HTMLBlock struct {
+	Range
+	Content  string // exact source lines, including the closing line
+	HTMLType int    // CommonMark HTML block type (1-7)
+}
+
+// Type returns the node type for HTMLBlock nodes.
+func (n *HTMLBlock) Type() NodeType { return NodeHTMLBlock }
+func (n *HTMLBlock) String() string {
+	return fmt.Sprintf("HTMLBlock(type=%d, content=%q)", n.HTMLType, n.Content)
+}
+
+// MathBlock represents a display math block that is kept verbatim
+type MathBlock struct {
+	Range
+	Content string // exact source lines, from the opening to the closing $$
+}
+
+// Type returns the node type for MathBlock nodes.
+func (n *MathBlock) Type() NodeType { return NodeMathBlock }
+func (n *MathBlock) String() string {
+	return fmt.Sprintf("MathBlock(content=%q)", n.Content)
+}
+
+// Admonition syntaxes, as written in the source
+const (
+	// AdmonitionSyntaxGitHub is a GitHub alert: a blockquote whose first
+	// line is the kind in brackets (> [!NOTE])
+	AdmonitionSyntaxGitHub = "github"
+	// AdmonitionSyntaxMkDocs is a MkDocs admonition: a marker line (!!! note
+	// "Title") followed by a body indented by four spaces
+	AdmonitionSyntaxMkDocs = "mkdocs"
+
+	// MkDocsAdmonitionMarker starts MkDocs admonitions that are not collapsible
+	MkDocsAdmonitionMarker = "!!!"
+)
+
+// Admonition represents a callout block with a kind, such as a note or a
+// warning, an optional title and a body of blocks
+type Admonition struct {
+	Range
+	Syntax string // "github" or "mkdocs"
+	// Marker is the MkDocs marker: "!!!", or "???" and "???+" for
+	// collapsible blocks. It is empty for GitHub alerts.
+	Marker string
+	// Kind is the kind as written, such as "NOTE" or "warning". MkDocs
+	// admonitions may list further CSS classes after the kind.
+	Kind string
+	// Title is the title of a MkDocs admonition as written, without its
+	// quotes. GitHub alerts have no title.
+	Title string
+	// HideTitle marks a MkDocs admonition with an empty title (""), which
+	// has no title bar
+	HideTitle bool
+	Children  []Node
+}
+
+// Type returns the node type for Admonition nodes.
+func (n *Admonition) Type() NodeType { return NodeAdmonition }
+func (n *Admonition) String() string {
+	return fmt.Sprintf("Admonition(syntax=%s, kind=%q, title=%q, children=%d)", n.Syntax, n.Kind, n.Title, len(n.Children))
+}
+
+// DefinitionList represents a PHP Markdown Extra definition list: terms, each
+// on its own line, followed by one or more definitions starting with a colon
+type DefinitionList struct {
+	Range
+	Children []Node // terms and definitions in document order
+}
+
+// Type returns the node type for DefinitionList nodes.
+func (n *DefinitionList) Type() NodeType { return NodeDefinitionList }
+func (n *DefinitionList) String() string {
+	return fmt.Sprintf("DefinitionList(children=%d)", len(n.Children))
+}
+
+// Term represents a term of a definition list
+type Term struct {
+	Range
+	Inlines []Node
+}
+
+// Type returns the node type for Term nodes.
+func (n *Term) Type() NodeType { return NodeTerm }
+func (n *Term) String() string {
+	return fmt.Sprintf("Term(text=%q)", n.Text())
+}
+
+// Text returns the plain text of the term
+func (n *Term) Text() string { return PlainText(n.Inlines) }
+
+// Definition represents a definition of the terms before it. Its body is
+// made of blocks.
+type Definition struct {
+	Range
+	// Tight is set when the definition directly follows the term or
+	// definition before it, without a blank line
+	Tight    bool
+	Children []Node
+}
+
+// Type returns the node type for Definition nodes.
+func (n *Definition) Type() NodeType { return NodeDefinition }
+func (n *Definition) String() string {
+	return fmt.Sprintf("Definition(tight=%t, children=%d)", n.Tight, len(n.Children))
+}
+
+// ESM represents a block of MDX import and export statements that is kept verbatim
+type ESM struct {
+	Range
+	Content string // exact source lines
+}
+
+// Type returns the node type for ESM nodes.
+func (n *ESM) Type() NodeType { return NodeESM }
+func (n *ESM) String() string {
+	return fmt.Sprintf("ESM(content=%q)", n.Content)
+}
+
+// JSX represents an MDX JSX element or expression on lines of its own. Its
+// tags are kept verbatim. An element whose opening tag stands alone holds
+// the Markdown up to its closing tag as children; other tags and
+// expressions only have Opening.
+type JSX struct {
+	Range
+	Opening string // exact source of the opening tag, or of all tags and expressions
+	Closing string // exact source of the closing tag, empty if there is none
+	// Indent is the number of columns the children were indented by
+	// relative to the tags
+	Indent int
+	// Tight is set when the children directly follow the opening tag,
+	// without a blank line
+	Tight    bool
+	Children []Node
+}
+
+// Type returns the node type for JSX nodes.
+func (n *JSX) Type() NodeType { return NodeJSX }
+func (n *JSX) String() string {
+	return fmt.Sprintf("JSX(opening=%q, children=%d)", n.Opening, len(n.Children))
+}
+
+// FrontMatter represents a metadata block at the very start of a document
+type FrontMatter struct {
+	Range
+	Format    string // "yaml" or "toml"
+	Delimiter string // opening delimiter line, "---" or "+++"
+	Closing   string // closing delimiter line, "---", "..." or "+++"
+	Content   string // everything between the delimiter lines
+}
+
+// Type returns the node type for FrontMatter nodes.
+func (n *FrontMatter) Type() NodeType { return NodeFrontMatter }
+func (n *FrontMatter) String() string {
+	return fmt.Sprintf("FrontMatter(format=%s, content=%q)", n.Format, n.Content)
+}
+
+// FootnoteReference represents an inline reference to a footnote definition
+type FootnoteReference struct {
+	Range
+	Label string
+}
+
+// Type returns the node type for FootnoteReference nodes.
+func (n *FootnoteReference) Type() NodeType { return NodeFootnoteReference }
+func (n *FootnoteReference) String() string {
+	return fmt.Sprintf("FootnoteReference(label=%q)", n.Label)
+}
+
+// FootnoteDefinition represents a footnote definition whose children are blocks
+type FootnoteDefinition struct {
+	Range
+	Label    string
+	Children []Node
+}
+
+// Type returns the node type for FootnoteDefinition nodes.
+func (n *FootnoteDefinition) Type() NodeType { return NodeFootnoteDefinition }
+func (n *FootnoteDefinition) String() string {
+	return fmt.Sprintf("FootnoteDefinition(label=%q, children=%d)", n.Label, len(n.Children))
+}
+
+// LinkReferenceDefinition represents a link reference definition such as
+// [label]: https://example.com "Title"
+type LinkReferenceDefinition struct {
+	Range
+	// Label is the label as written, with inner whitespace collapsed
+	Label string
+	// Destination is the link destination without angle brackets
+	Destination string
+	// Title is the optional title without its delimiters
+	Title string
+}
+
+// Type returns the node type for LinkReferenceDefinition nodes.
+func (n *LinkReferenceDefinition) Type() NodeType { return NodeLinkReferenceDefinition }
+func (n *LinkReferenceDefinition) String() string {
+	return fmt.Sprintf("LinkReferenceDefinition(label=%q, destination=%q, title=%q)", n.Label, n.Destination, n.Title)
+}
+
+// NormalizedLabel returns the label used to match references to this definition
+func (n *LinkReferenceDefinition) NormalizedLabel() string {
+	return NormalizeLinkLabel(n.Label)
+}
+
+// NormalizeLinkLabel normalizes a link label the way CommonMark matches
+// references: case-insensitively and with whitespace runs collapsed
+func NormalizeLinkLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// ReferenceStyle describes how a link or image names its destination
+type ReferenceStyle int
+
+const (
+	// ReferenceInline is an inline destination: [text](url)
+	ReferenceInline ReferenceStyle = iota
+	// ReferenceFull is a full reference: [text][label]
+	ReferenceFull
+	// ReferenceCollapsed is a collapsed reference: [label][]
+	ReferenceCollapsed
+	// ReferenceShortcut is a shortcut reference: [label]
+	ReferenceShortcut
+)
+
+// Image represents an inline image. Its children are the alt text with
+// inline markup, so that images render back exactly as written.
+type Image struct {
+	Range
+	Children    []Node
+	Destination string
+	Title       string
+	// Reference is how the image refers to its destination, and Label is the
+	// reference label. Only full references write the label out; collapsed
+	// and shortcut references use the alt text.
+	Reference ReferenceStyle
+	Label     string
+}
+
+// Type returns the node type for Image nodes.
+func (n *Image) Type() NodeType { return NodeImage }
+func (n *Image) String() string {
+	return fmt.Sprintf("Image(alt=%q, destination=%q, title=%q)", PlainText(n.Children), n.Destination, n.Title)
+}
+
+// Link represents an inline or reference link
+type Link struct {
+	Range
+	Children    []Node
+	Destination string
+	Title       string
+	// Reference is how the link refers to its destination, and Label is the
+	// reference label. Only full references write the label out; collapsed
+	// and shortcut references use the link text.
+	Reference ReferenceStyle
+	Label     string
+}
+
+// Type returns the node type for Link nodes.
+func (n *Link) Type() NodeType { return NodeLink }
+func (n *Link) String() string {
+	return fmt.Sprintf("Link(text=%q, destination=%q, title=%q)", PlainText(n.Children), n.Destination, n.Title)
+}
+
+// Emphasis represents emphasized text
+type Emphasis struct {
+	Range
+	Marker   string // "*" or "_"
+	Children []Node
+}
+
+// Type returns the node type for Emphasis nodes.
+func (n *Emphasis) Type() NodeType { return NodeEmphasis }
+func (n *Emphasis) String() string {
+	return fmt.Sprintf("Emphasis(marker=%q, text=%q)", n.Marker, PlainText(n.Children))
+}
+
+// Strong represents strongly emphasized text
+type Strong struct {
+	Range
+	Marker   string // "**" or "__"
+	Children []Node
+}
+
+// Type returns the node type for Strong nodes.
+func (n *Strong) Type() NodeType { return NodeStrong }
+func (n *Strong) String() string {
+	return fmt.Sprintf("Strong(marker=%q, text=%q)", n.Marker, PlainText(n.Children))
+}
+
+// Strikethrough represents GFM strikethrough text
+type Strikethrough struct {
+	Range
+	Children []Node
+}
+
+// Type returns the node type for Strikethrough nodes.
+func (n *Strikethrough) Type() NodeType { return NodeStrikethrough }
+func (n *Strikethrough) String() string {
+	return fmt.Sprintf("Strikethrough(text=%q)", PlainText(n.Children))
+}
+
+// CodeSpan represents inline code. Content is the code without the
+// backtick delimiters, with line endings turned into spaces.
+type CodeSpan struct {
+	Range
+	Content string
+}
+
+// Type returns the node type for CodeSpan nodes.
+func (n *CodeSpan) Type() NodeType { return NodeCodeSpan }
+func (n *CodeSpan) String() string {
+	return fmt.Sprintf("CodeSpan(content=%q)", n.Content)
+}
+
+// Autolink represents a URL or email address that is a link by itself
+type Autolink struct {
+	Range
+	URL   string
+	Bare  bool // true for GFM bare URLs, false for <url>
+	Email bool // true for email addresses
+}
+
+// Type returns the node type for Autolink nodes.
+func (n *Autolink) Type() NodeType { return NodeAutolink }
+func (n *Autolink) String() string {
+	return fmt.Sprintf("Autolink(url=%q, bare=%t, email=%t)", n.URL, n.Bare, n.Email)
+}
+
+// RawHTML represents inline HTML that is kept verbatim
+type RawHTML struct {
+	Range
+	Content string
+}
+
+// Type returns the node type for RawHTML nodes.
+func (n *RawHTML) Type() NodeType { return NodeRawHTML }
+func (n *RawHTML) String() string {
+	return fmt.Sprintf("RawHTML(content=%q)", n.Content)
+}
+
+// SoftBreak represents a line ending inside inline content. It renders as a
+// line ending, or as a space when text is rewrapped.
+type SoftBreak struct {
+	Range
+}
+
+// Type returns the node type for SoftBreak nodes.
+func (n *SoftBreak) Type() NodeType { return NodeSoftBreak }
+func (n *SoftBreak) String() string { return "SoftBreak" }
+
+// HardBreak represents a hard line break
+type HardBreak struct {
+	Range
+	Marker string // "\\" or "  "
+}
+
+// Type returns the node type for HardBreak nodes.
+func (n *HardBreak) Type() NodeType { return NodeHardBreak }
+func (n *HardBreak) String() string {
+	return fmt.Sprintf("HardBreak(marker=%q)", n.Marker)
+}
+
+// InlineMath represents a math formula inside text that is kept verbatim
+type InlineMath struct {
+	Range
+	Content string // exact source, including the $ or $$ delimiters
+}
+
+// Type returns the node type for InlineMath nodes.
+func (n *InlineMath) Type() NodeType { return NodeInlineMath }
+func (n *InlineMath) String() string {
+	return fmt.Sprintf("InlineMath(content=%q)", n.Content)
+}
+
+// PlainText returns the text of inline nodes without markup. Soft breaks
+// become spaces and hard breaks line endings; raw HTML and footnote
+// references are left out.
+func PlainText(nodes []Node) string {
+	var sb strings.Builder
+	writePlainText(&sb, nodes)
+	return sb.String()
+}
+
+// writePlainText writes the plain text of inline nodes to sb
+func writePlainText(sb *strings.Builder, nodes []Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			sb.WriteString(n.Content)
+		case *CodeSpan:
+			sb.WriteString(n.Content)
+		case *Autolink:
+			sb.WriteString(n.URL)
+		case *InlineMath:
+			sb.WriteString(n.Content)
+		case *SoftBreak:
+			sb.WriteString(" ")
+		case *HardBreak:
+			sb.WriteString("\n")
+		case *Emphasis:
+			writePlainText(sb, n.Children)
+		case *Strong:
+			writePlainText(sb, n.Children)
+		case *Strikethrough:
+			writePlainText(sb, n.Children)
+		case *Link:
+			writePlainText(sb, n.Children)
+		case *Image:
+			writePlainText(sb, n.Children)
+		}
+	}
+}
+
 // Walker provides a simple way to iterate over nodes
 type Walker struct {
 	nodes []Node
 	index int
 }
 
-// NewWalker creates a new walker for the given document
+// NewWalker creates a new walker for the given document. It visits the
+// document and all of its descendants in document order. Use Visit to change
+// the tree while traversing it.
 func NewWalker(doc *Document) *Walker {
-	nodes := append([]Node{doc}, doc.Children...)
+	var nodes []Node
+	Inspect(doc, func(node Node) bool {
+		nodes = append(nodes, node)
+		return true
+	})
 	return &Walker{nodes: nodes, index: -1}
 }
 
@@ -154,6 +802,62 @@ func NodeTypeString(t NodeType) string {
 		return "CodeBlock"
 	case NodeText:
 		return "Text"
+	case NodeBlockquote:
+		return "Blockquote"
+	case NodeTable:
+		return "Table"
+	case NodeTableRow:
+		return "TableRow"
+	case NodeTableCell:
+		return "TableCell"
+	case NodeThematicBreak:
+		return "ThematicBreak"
+	case NodeHTMLBlock:
+		return "HTMLBlock"
+	case NodeFrontMatter:
+		return "FrontMatter"
+	case NodeFootnoteReference:
+		return "FootnoteReference"
+	case NodeFootnoteDefinition:
+		return "FootnoteDefinition"
+	case NodeLinkReferenceDefinition:
+		return "LinkReferenceDefinition"
+	case NodeImage:
+		return "Image"
+	case NodeEmphasis:
+		return "Emphasis"
+	case NodeStrong:
+		return "Strong"
+	case NodeStrikethrough:
+		return "Strikethrough"
+	case NodeCodeSpan:
+		return "CodeSpan"
+	case NodeLink:
+		return "Link"
+	case NodeAutolink:
+		return "Autolink"
+	case NodeRawHTML:
+		return "RawHTML"
+	case NodeSoftBreak:
+		return "SoftBreak"
+	case NodeHardBreak:
+		return "HardBreak"
+	case NodeMathBlock:
+		return "MathBlock"
+	case NodeInlineMath:
+		return "InlineMath"
+	case NodeAdmonition:
+		return "Admonition"
+	case NodeDefinitionList:
+		return "DefinitionList"
+	case NodeTerm:
+		return "Term"
+	case NodeDefinition:
+		return "Definition"
+	case NodeESM:
+		return "ESM"
+	case NodeJSX:
+		return "JSX"
 	default:
 		return "Unknown"
 	}
@@ -163,6 +867,11 @@ func NodeTypeString(t NodeType) string {
 func DebugString(doc *Document) string {
 	var sb strings.Builder
 	sb.WriteString("Document\n")
+	if doc.FrontMatter != nil {
+		sb.WriteString("  ")
+		sb.WriteString(doc.FrontMatter.String())
+		sb.WriteString("\n")
+	}
 	for _, child := range doc.Children {
 		sb.WriteString("  ")
 		sb.WriteString(child.String())
@@ -171,7 +880,15 @@ func DebugString(doc *Document) string {
 	return sb.String()
 }
 
-// GetAllNodes returns all nodes in the document as a flat slice.
+// GetAllNodes returns all nodes below the document as a flat slice, in
+// document order.
 func (n *Document) GetAllNodes() []Node {
-	return append([]Node{}, n.Children...)
+	var nodes []Node
+	Inspect(n, func(node Node) bool {
+		if node != n {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return nodes
 }
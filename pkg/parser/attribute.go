@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// idAttribute is the name goldmark gives the {#id} attribute
+	idAttribute = "id"
+	// classAttribute is the name goldmark gives {.class} attributes
+	classAttribute = "class"
+)
+
+// convertHeadingAttributes copies the attribute block written after a
+// heading's text into the heading. The block is read again from the source,
+// as goldmark keeps only the parsed attributes.
+func convertHeadingAttributes(heading *Heading, n *ast.Heading, source []byte) {
+	lines := n.Lines()
+	if lines.Len() == 0 {
+		return
+	}
+	end := lines.At(lines.Len() - 1).Stop
+	rest := source[end:lineEnd(source, end)]
+	brace := bytes.IndexByte(rest, '{')
+	if brace < 0 {
+		return
+	}
+	attributes, ok := gmparser.ParseAttributes(text.NewReader(rest[brace:]))
+	if !ok {
+		return
+	}
+
+	for _, attribute := range attributes {
+		value := attributeValue(attribute.Value)
+		switch string(attribute.Name) {
+		case idAttribute:
+			heading.ID = value
+		case classAttribute:
+			heading.Classes = append(heading.Classes, strings.Fields(value)...)
+		default:
+			heading.Attributes = append(heading.Attributes, Attribute{Key: string(attribute.Name), Value: value})
+		}
+	}
+
+	// The space that separated the text from the block is not part of the text
+	if last := len(heading.Inlines) - 1; last >= 0 {
+		if text, ok := heading.Inlines[last].(*Text); ok {
+			text.Content = strings.TrimRight(text.Content, " \t")
+		}
+	}
+}
+
+// attributeValue returns an attribute value parsed by goldmark as text
+func attributeValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// footnoteBlockParserPriority matches the priority used by goldmark's footnote extension
+	footnoteBlockParserPriority = 999
+	// footnoteInlineParserPriority matches the priority used by goldmark's footnote extension
+	footnoteInlineParserPriority = 101
+	// footnoteTransformerPriority matches the priority of goldmark's footnote transformer
+	footnoteTransformerPriority = 999
+)
+
+// footnotePlacesKey stores the footnotePlace of every definition parsed so far
+var footnotePlacesKey = gmparser.NewContextKey()
+
+// footnoteExtension enables goldmark's footnote parsers without its AST
+// transformer. The transformer is meant for HTML output: it drops definitions
+// that are never referenced and appends backlinks, both of which would lose
+// or invent content when formatting. Definitions are put back where they
+// were written instead.
+var footnoteExtension goldmark.Extender = &footnotes{}
+
+type footnotes struct{}
+
+// Extend registers the footnote block and inline parsers
+func (e *footnotes) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(
+			util.Prioritized(footnoteBlockParser{extension.NewFootnoteBlockParser()}, footnoteBlockParserPriority),
+		),
+		gmparser.WithInlineParsers(
+			util.Prioritized(&spanParser{extension.NewFootnoteParser()}, footnoteInlineParserPriority),
+		),
+		gmparser.WithASTTransformers(
+			util.Prioritized(footnoteTransformer{}, footnoteTransformerPriority),
+		),
+	)
+}
+
+// footnotePlace is where a footnote definition was written: its parent and
+// the sibling before it, if any
+type footnotePlace struct {
+	footnote, parent, previous ast.Node
+}
+
+// footnoteBlockParser wraps goldmark's footnote block parser, which moves
+// every definition into a single footnote list where the first one was
+// written. The inline parser looks references up in that list, so it is
+// left in place until inlines are parsed, but the place of each definition
+// is recorded first.
+type footnoteBlockParser struct {
+	gmparser.BlockParser
+}
+
+// Close records where the definition was written and hands it to goldmark
+func (b footnoteBlockParser) Close(node ast.Node, reader text.Reader, pc gmparser.Context) {
+	places, _ := pc.Get(footnotePlacesKey).([]footnotePlace)
+	place := footnotePlace{footnote: node, parent: node.Parent(), previous: node.PreviousSibling()}
+	pc.Set(footnotePlacesKey, append(places, place))
+	b.BlockParser.Close(node, reader, pc)
+}
+
+// footnoteTransformer moves footnote definitions out of goldmark's footnote
+// list and back to where they were written
+type footnoteTransformer struct{}
+
+// Transform restores the recorded places. They are restored last to first,
+// so that a definition whose recorded sibling was taken out by a later one
+// is inserted before it, and the emptied footnote list is removed.
+func (t footnoteTransformer) Transform(_ *ast.Document, _ text.Reader, pc gmparser.Context) {
+	places, _ := pc.Get(footnotePlacesKey).([]footnotePlace)
+	var list ast.Node
+	for i := len(places) - 1; i >= 0; i-- {
+		place := places[i]
+		list = place.footnote.Parent()
+		list.RemoveChild(list, place.footnote)
+		switch {
+		case place.previous != nil && place.previous.Parent() == place.parent:
+			place.parent.InsertAfter(place.parent, place.previous, place.footnote)
+		case place.previous == nil && place.parent.HasChildren():
+			place.parent.InsertBefore(place.parent, place.parent.FirstChild(), place.footnote)
+		default:
+			place.parent.AppendChild(place.parent, place.footnote)
+		}
+	}
+	if list != nil && list.Parent() != nil {
+		list.Parent().RemoveChild(list.Parent(), list)
+	}
+	pc.Set(footnotePlacesKey, nil)
+}
+
+// convertFootnote converts a goldmark footnote into a footnote definition block
+func (p *GoldmarkParser) convertFootnote(n ast.Node, source []byte) Node {
+	footnote := n.(*extast.Footnote)
+	return &FootnoteDefinition{
+		Label:    string(footnote.Ref),
+		Children: p.convertChildren(footnote, source),
+	}
+}
+
+// convertFootnoteLink converts a footnote link into a reference. goldmark only
+// records the footnote index on links, so the label is looked up on the
+// matching definition.
+func (p *GoldmarkParser) convertFootnoteLink(n ast.Node) *FootnoteReference {
+	link := n.(*extast.FootnoteLink)
+	ref := &FootnoteReference{}
+
+	_ = ast.Walk(n.OwnerDocument(), func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if footnote, ok := node.(*extast.Footnote); ok && footnote.Index == link.Index {
+			ref.Label = string(footnote.Ref)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return ref
+}
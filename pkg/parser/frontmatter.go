@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	// FrontMatterYAML is the format name of YAML front matter
+	FrontMatterYAML = "yaml"
+	// FrontMatterTOML is the format name of TOML front matter
+	FrontMatterTOML = "toml"
+
+	yamlDelimiter    = "---"
+	yamlEndDelimiter = "..."
+	tomlDelimiter    = "+++"
+)
+
+// extractFrontMatter detects a front matter block at the start of content.
+// It returns the front matter node and a copy of content in which the front
+// matter lines are blanked out, so goldmark never sees them while byte offsets
+// and line numbers of the remaining document stay unchanged.
+func extractFrontMatter(content []byte) (*FrontMatter, []byte) {
+	firstLine, rest, found := bytes.Cut(content, []byte("\n"))
+	if !found {
+		return nil, content
+	}
+
+	delimiter := strings.TrimRight(string(firstLine), " \t\r")
+	format := ""
+	closings := []string{}
+	switch delimiter {
+	case yamlDelimiter:
+		format = FrontMatterYAML
+		closings = []string{yamlDelimiter, yamlEndDelimiter}
+	case tomlDelimiter:
+		format = FrontMatterTOML
+		closings = []string{tomlDelimiter}
+	default:
+		return nil, content
+	}
+
+	offset := len(firstLine) + 1
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		trimmed := strings.TrimRight(string(line), " \t\r")
+
+		for _, closing := range closings {
+			if trimmed != closing {
+				continue
+			}
+
+			end := offset + len(line)
+			if end < len(content) {
+				end++ // include the newline after the closing delimiter
+			}
+
+			frontMatter := &FrontMatter{
+				Format:    format,
+				Delimiter: delimiter,
+				Closing:   closing,
+				Content:   string(content[len(firstLine)+1 : offset]),
+			}
+			setSpan(frontMatter, sourceSpan{0, offset + len(line)})
+			return frontMatter, blankOut(content, end)
+		}
+
+		offset += len(line) + 1
+		rest = next
+	}
+
+	return nil, content
+}
+
+// blankOut returns a copy of content with every byte before end, except line
+// breaks, replaced by a space
+func blankOut(content []byte, end int) []byte {
+	blanked := make([]byte, len(content))
+	copy(blanked, content)
+	for i := 0; i < end; i++ {
+		if blanked[i] != '\n' {
+			blanked[i] = ' '
+		}
+	}
+	return blanked
+}
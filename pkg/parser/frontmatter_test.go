@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestExtractFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantFormat  string
+		wantContent string
+		wantClosing string
+	}{
+		{
+			name:        "yaml front matter",
+			content:     "---\ntitle: Hello\n---\n# Heading\n",
+			wantFormat:  FrontMatterYAML,
+			wantContent: "title: Hello\n",
+			wantClosing: "---",
+		},
+		{
+			name:        "yaml front matter closed with dots",
+			content:     "---\ntitle: Hello\n...\nText\n",
+			wantFormat:  FrontMatterYAML,
+			wantContent: "title: Hello\n",
+			wantClosing: "...",
+		},
+		{
+			name:        "toml front matter",
+			content:     "+++\ntitle = \"Hello\"\n+++\nText\n",
+			wantFormat:  FrontMatterTOML,
+			wantContent: "title = \"Hello\"\n",
+			wantClosing: "+++",
+		},
+		{
+			name:        "empty front matter at end of file",
+			content:     "---\n---",
+			wantFormat:  FrontMatterYAML,
+			wantContent: "",
+			wantClosing: "---",
+		},
+		{
+			name:    "unclosed front matter",
+			content: "---\ntitle: Hello\n",
+		},
+		{
+			name:    "front matter not at start",
+			content: "Text\n---\ntitle: Hello\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontMatter, rest := extractFrontMatter([]byte(tt.content))
+			if len(rest) != len(tt.content) {
+				t.Errorf("Expected remaining content to keep length %d, got %d", len(tt.content), len(rest))
+			}
+
+			if tt.wantFormat == "" {
+				if frontMatter != nil {
+					t.Fatalf("Expected no front matter, got %v", frontMatter)
+				}
+				if string(rest) != tt.content {
+					t.Errorf("Expected content to be unchanged, got %q", rest)
+				}
+				return
+			}
+
+			if frontMatter == nil {
+				t.Fatal("Expected front matter, got nil")
+			}
+			if frontMatter.Format != tt.wantFormat {
+				t.Errorf("Expected format %q, got %q", tt.wantFormat, frontMatter.Format)
+			}
+			if frontMatter.Content != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, frontMatter.Content)
+			}
+			if frontMatter.Closing != tt.wantClosing {
+				t.Errorf("Expected closing %q, got %q", tt.wantClosing, frontMatter.Closing)
+			}
+		})
+	}
+}
+
+func TestGoldmarkParser_ParseFrontMatter(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte("---\ntitle: Hello\nsubtitle: World\n---\n\n# Heading\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if doc.FrontMatter == nil {
+		t.Fatal("Expected front matter on document")
+	}
+
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected only the heading as child, got %d children", len(doc.Children))
+	}
+
+	heading, ok := doc.Children[0].(*Heading)
+	if !ok || heading.Text() != "Heading" {
+		t.Errorf("Expected heading 'Heading', got %v", doc.Children[0])
+	}
+}
@@ -3,13 +3,18 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
 	gmparser "github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
 )
 
 const (
@@ -20,47 +25,174 @@ const (
 // GoldmarkParser implements the Parser interface using goldmark
 type GoldmarkParser struct {
 	markdown goldmark.Markdown
+	// alerts is set when blockquotes that start with an alert marker are
+	// read as GitHub alerts
+	alerts bool
+}
+
+// Option enables an optional Markdown dialect feature in a GoldmarkParser
+type Option func(*parserOptions)
+
+// parserOptions holds the optional features a parser is created with
+type parserOptions struct {
+	// extensions are the syntax extensions to parse, nil for those of the
+	// GFM dialect
+	extensions      []string
+	definitionLists bool
+	mdx             bool
+}
+
+// syntaxExtensions are the goldmark extensions behind each syntax extension
+// name of a dialect. GitHub alerts and heading attributes are not goldmark
+// extensions and are handled by the parser itself.
+var syntaxExtensions = map[string]goldmark.Extender{
+	config.ExtensionTables:          extension.Table,
+	config.ExtensionStrikethrough:   extension.Strikethrough,
+	config.ExtensionTaskLists:       extension.TaskList,
+	config.ExtensionAutolinks:       extension.Linkify,
+	config.ExtensionFootnotes:       footnoteExtension, // keeping unused definitions
+	config.ExtensionMath:            mathExtension,     // kept as written
+	config.ExtensionAdmonitions:     admonitionExtension,
+	config.ExtensionDefinitionLists: extension.DefinitionList,
+}
+
+// WithExtensions parses only the named syntax extensions, such as
+// config.ExtensionTables, instead of those of the GFM dialect. Without
+// any, documents are parsed as strict CommonMark.
+func WithExtensions(names ...string) Option {
+	return func(o *parserOptions) {
+		o.extensions = append([]string{}, names...)
+	}
+}
+
+// WithDefinitionLists enables PHP Markdown Extra definition lists: terms on
+// their own lines, followed by definitions that start with a colon
+func WithDefinitionLists() Option {
+	return func(o *parserOptions) {
+		o.definitionLists = true
+	}
+}
+
+// WithMDX parses documents as MDX: import and export statements and JSX
+// are kept as written, and the Markdown between and inside JSX elements is
+// parsed as usual
+func WithMDX() Option {
+	return func(o *parserOptions) {
+		o.mdx = true
+	}
 }
 
-// NewGoldmarkParser creates a new goldmark-based parser
-func NewGoldmarkParser() *GoldmarkParser {
+// NewGoldmarkParser creates a new goldmark-based parser. Unless the
+// options select other extensions, it parses the GFM dialect.
+func NewGoldmarkParser(opts ...Option) *GoldmarkParser {
+	var options parserOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	names := options.extensions
+	if names == nil {
+		names = config.Dialect{Preset: config.DialectGFM}.ExtensionNames()
+	}
+	if options.definitionLists {
+		names = append(append([]string{}, names...), config.ExtensionDefinitionLists)
+	}
+
+	var extensions []goldmark.Extender
+	// Heading IDs are generated after conversion, the way GitHub does
+	var parserOptions []gmparser.Option
+	alerts := false
+	for _, name := range names {
+		switch name {
+		case config.ExtensionAlerts:
+			alerts = true
+		case config.ExtensionHeadingAttributes:
+			parserOptions = append(parserOptions, gmparser.WithHeadingAttribute())
+		default:
+			if extender, ok := syntaxExtensions[name]; ok {
+				extensions = append(extensions, extender)
+			}
+		}
+	}
+	if options.mdx {
+		extensions = append(extensions, mdxExtension)
+	}
+
 	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,           // GitHub Flavored Markdown
-			extension.Table,         // Tables support
-			extension.Strikethrough, // Strikethrough support
-			extension.TaskList,      // Task lists support
-		),
-		goldmark.WithParserOptions(
-			gmparser.WithAutoHeadingID(), // Auto-generate heading IDs
-		),
+		goldmark.WithParser(newMarkdownParser()),
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
 	)
 
 	return &GoldmarkParser{
 		markdown: md,
+		alerts:   alerts,
 	}
 }
 
+// NewGoldmarkParserFromConfig creates a goldmark-based parser for the
+// Markdown dialect selected in the configuration
+func NewGoldmarkParserFromConfig(cfg *config.Config, opts ...Option) *GoldmarkParser {
+	return NewGoldmarkParser(append([]Option{WithExtensions(cfg.Markdown.Extensions()...)}, opts...)...)
+}
+
+// newMarkdownParser creates goldmark's CommonMark parser with the link
+// parser and link reference transformer replaced by versions that keep
+// reference links and definitions as written
+func newMarkdownParser() gmparser.Parser {
+	return gmparser.NewParser(
+		gmparser.WithBlockParsers(gmparser.DefaultBlockParsers()...),
+		gmparser.WithInlineParsers(inlineParsers()...),
+		gmparser.WithParagraphTransformers(
+			util.Prioritized(linkReferenceTransformer, linkReferenceTransformerPriority),
+		),
+	)
+}
+
 // Parse parses the given markdown content and returns an AST
 func (p *GoldmarkParser) Parse(content []byte) (*Document, error) {
+	// Front matter is not Markdown, so it is taken out before goldmark sees it
+	frontMatter, source := extractFrontMatter(content)
+
 	// Parse with goldmark
-	reader := text.NewReader(content)
+	reader := text.NewReader(source)
 	doc := p.markdown.Parser().Parse(reader)
 
 	// Convert goldmark AST to our AST
 	ourDoc := &Document{
-		Children: make([]Node, 0),
+		FrontMatter: frontMatter,
+		Children:    p.convertChildren(doc, source),
 	}
+	resolvePositions(ourDoc, content)
+	assignHeadingIDs(ourDoc)
+
+	return ourDoc, nil
+}
+
+// convertChildren converts all block children of a goldmark container node
+func (p *GoldmarkParser) convertChildren(n ast.Node, source []byte) []Node {
+	return p.convertBlocks(n.FirstChild(), source)
+}
+
+// convertBlocks converts a goldmark block node and all of its following siblings
+func (p *GoldmarkParser) convertBlocks(first ast.Node, source []byte) []Node {
+	children := make([]Node, 0)
+
+	for child := first; child != nil; child = child.NextSibling() {
+		if child.Kind() == kindLinkReferenceBlock {
+			children = append(children, p.convertLinkReferenceBlock(child, source)...)
+			continue
+		}
 
-	// Walk through goldmark AST and convert only top-level nodes
-	for child := doc.FirstChild(); child != nil; child = child.NextSibling() {
-		ourNode := p.convertNode(child, content)
+		ourNode := p.convertNode(child, source)
 		if ourNode != nil {
-			ourDoc.Children = append(ourDoc.Children, ourNode)
+			if span, ok := nodeSpan(child, source); ok {
+				setSpan(ourNode, span)
+			}
+			children = append(children, ourNode)
 		}
 	}
-
-	return ourDoc, nil
+	return children
 }
 
 // convertNode converts a goldmark AST node to our AST node
@@ -70,10 +202,37 @@ func (p *GoldmarkParser) convertNode(n ast.Node, source []byte) Node {
 		return p.convertHeading(n, source)
 	case ast.KindParagraph:
 		return p.convertParagraph(n, source)
+	case ast.KindTextBlock:
+		// Text blocks are the paragraphs of tight list items. An empty one
+		// is left behind by a paragraph that only held link definitions.
+		if n.Lines().Len() == 0 {
+			return nil
+		}
+		return p.convertParagraph(n, source)
 	case ast.KindList:
 		return p.convertList(n, source)
 	case ast.KindFencedCodeBlock, ast.KindCodeBlock:
 		return p.convertCodeBlock(n, source)
+	case ast.KindBlockquote:
+		return p.convertBlockquote(n, source)
+	case extast.KindTable:
+		return p.convertTable(n, source)
+	case ast.KindThematicBreak:
+		return &ThematicBreak{}
+	case ast.KindHTMLBlock:
+		return p.convertHTMLBlock(n, source)
+	case extast.KindFootnote:
+		return p.convertFootnote(n, source)
+	case kindMathBlock:
+		return p.convertMathBlock(n, source)
+	case kindAdmonition:
+		return p.convertAdmonition(n, source)
+	case extast.KindDefinitionList:
+		return p.convertDefinitionList(n, source)
+	case kindESM:
+		return p.convertESM(n, source)
+	case kindJSX:
+		return p.convertJSX(n, source)
 	case ast.KindText, ast.KindString:
 		return p.convertText(n, source)
 	default:
@@ -84,19 +243,40 @@ func (p *GoldmarkParser) convertNode(n ast.Node, source []byte) Node {
 // convertHeading converts a heading node
 func (p *GoldmarkParser) convertHeading(n ast.Node, source []byte) Node {
 	heading := n.(*ast.Heading)
-	text := p.extractText(n, source)
-	text = strings.Join(strings.Fields(text), " ")
-	return &Heading{
-		Level: heading.Level,
-		Text:  strings.TrimSpace(text),
-		Style: "atx",
+	ourHeading := &Heading{
+		Level:   heading.Level,
+		Inlines: p.convertInlines(n, source),
+		Style:   headingStyle(heading, source),
+	}
+	convertHeadingAttributes(ourHeading, heading, source)
+	return ourHeading
+}
+
+// headingStyle detects how a heading was written. goldmark keeps only the
+// heading text, so the style is read from the source around it.
+func headingStyle(heading *ast.Heading, source []byte) string {
+	lines := heading.Lines()
+	if lines.Len() == 0 {
+		return HeadingStyleATX // setext headings always have text
+	}
+
+	start := lines.At(0).Start
+	if headingStart(source, start) == start {
+		return HeadingStyleSetext
+	}
+
+	// A closing sequence may be followed by an attribute block
+	end := lines.At(lines.Len() - 1).Stop
+	if rest := bytes.TrimLeft(source[end:lineEnd(source, end)], " \t"); len(rest) > 0 && rest[0] == '#' {
+		return HeadingStyleATXClosed
 	}
+	return HeadingStyleATX
 }
 
 // convertParagraph converts a paragraph node
 func (p *GoldmarkParser) convertParagraph(n ast.Node, source []byte) Node {
 	return &Paragraph{
-		Text: p.extractText(n, source),
+		Inlines: p.convertInlines(n, source),
 	}
 }
 
@@ -105,42 +285,185 @@ func (p *GoldmarkParser) convertList(n ast.Node, source []byte) Node {
 	list := n.(*ast.List)
 	ourList := &List{
 		Ordered: list.IsOrdered(),
+		Start:   list.Start,
+		Tight:   list.IsTight,
 		Items:   make([]*ListItem, 0),
 		Marker:  p.getListMarker(list),
 	}
 
 	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
 		if child.Kind() == ast.KindListItem {
-			item := p.convertListItem(child, source)
+			item := p.convertListItem(child, source, list.Start+len(ourList.Items))
+			if span, ok := nodeSpan(child, source); ok {
+				setSpan(item, span)
+			}
 			ourList.Items = append(ourList.Items, item)
 		}
 	}
 	return ourList
 }
 
-// convertListItem converts a list item node
-func (p *GoldmarkParser) convertListItem(n ast.Node, source []byte) *ListItem {
+// convertListItem converts a list item node. number is the item's number
+// counting from the start of an ordered list.
+func (p *GoldmarkParser) convertListItem(n ast.Node, source []byte, number int) *ListItem {
 	item := &ListItem{
-		Text:     p.extractText(n, source),
-		Marker:   p.getListItemMarker(n.(*ast.ListItem)),
-		Children: make([]Node, 0),
+		Inlines: make([]Node, 0),
+		Marker:  p.getListItemMarker(n.(*ast.ListItem), source, number),
+		Task:    taskState(n),
+	}
+
+	// The text that follows the marker is the item's own content; any
+	// further blocks are its children
+	first := n.FirstChild()
+	if first != nil && (first.Kind() == ast.KindTextBlock || first.Kind() == ast.KindParagraph) {
+		item.Inlines = p.convertInlines(first, source)
+		first = first.NextSibling()
 	}
+	item.Children = p.convertBlocks(first, source)
+	return item
+}
+
+// taskState returns the state of the task checkbox that starts a list item.
+// goldmark parses it as the first inline of the item's first block.
+func taskState(n ast.Node) TaskState {
+	if n.FirstChild() == nil {
+		return TaskNone
+	}
+	checkBox, ok := n.FirstChild().FirstChild().(*extast.TaskCheckBox)
+	switch {
+	case !ok:
+		return TaskNone
+	case checkBox.IsChecked:
+		return TaskChecked
+	default:
+		return TaskUnchecked
+	}
+}
 
-	for nestedChild := n.FirstChild(); nestedChild != nil; nestedChild = nestedChild.NextSibling() {
-		if nestedChild.Kind() == ast.KindList {
-			nestedList := p.convertNode(nestedChild, source)
-			if nestedList != nil {
-				item.Children = append(item.Children, nestedList)
+// convertBlockquote converts a blockquote node and all of its block
+// children. With alerts enabled, blockquotes that start with an alert marker
+// are GitHub alerts.
+func (p *GoldmarkParser) convertBlockquote(n ast.Node, source []byte) Node {
+	children := p.convertChildren(n, source)
+	if p.alerts {
+		if alert := githubAlert(n, children, source); alert != nil {
+			return alert
+		}
+	}
+	return &Blockquote{
+		Children: children,
+	}
+}
+
+// convertDefinitionList converts a definition list with its terms and definitions
+func (p *GoldmarkParser) convertDefinitionList(n ast.Node, source []byte) Node {
+	list := &DefinitionList{Children: make([]Node, 0)}
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		var item Node
+		switch child := child.(type) {
+		case *extast.DefinitionTerm:
+			item = &Term{Inlines: p.convertInlines(child, source)}
+		case *extast.DefinitionDescription:
+			item = &Definition{
+				Tight:    child.IsTight,
+				Children: p.convertChildren(child, source),
 			}
+		default:
+			continue
+		}
+		if span, ok := nodeSpan(child, source); ok {
+			setSpan(item, span)
 		}
+		list.Children = append(list.Children, item)
+	}
+	return list
+}
+
+// convertTable converts a GFM table node with its header and body rows
+func (p *GoldmarkParser) convertTable(n ast.Node, source []byte) Node {
+	table := n.(*extast.Table)
+	ourTable := &Table{
+		Alignments: make([]Alignment, 0, len(table.Alignments)),
+		Rows:       make([]*TableRow, 0),
+	}
+
+	for _, alignment := range table.Alignments {
+		ourTable.Alignments = append(ourTable.Alignments, convertAlignment(alignment))
+	}
+
+	for child := table.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.Kind() {
+		case extast.KindTableHeader:
+			ourTable.Header = p.convertTableRow(child, source)
+		case extast.KindTableRow:
+			ourTable.Rows = append(ourTable.Rows, p.convertTableRow(child, source))
+		}
+	}
+	return ourTable
+}
+
+// convertTableRow converts a table header or body row
+func (p *GoldmarkParser) convertTableRow(n ast.Node, source []byte) *TableRow {
+	row := &TableRow{
+		Cells: make([]*TableCell, 0),
+	}
+	if span, ok := nodeSpan(n, source); ok {
+		setSpan(row, span)
+	}
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if cell, ok := child.(*extast.TableCell); ok {
+			ourCell := &TableCell{
+				Inlines:   p.convertInlines(cell, source),
+				Alignment: convertAlignment(cell.Alignment),
+			}
+			if span, ok := nodeSpan(cell, source); ok {
+				setSpan(ourCell, span)
+			}
+			row.Cells = append(row.Cells, ourCell)
+		}
+	}
+	return row
+}
+
+// convertAlignment maps a goldmark table alignment to our alignment
+func convertAlignment(alignment extast.Alignment) Alignment {
+	switch alignment {
+	case extast.AlignLeft:
+		return AlignLeft
+	case extast.AlignCenter:
+		return AlignCenter
+	case extast.AlignRight:
+		return AlignRight
+	default:
+		return AlignNone
+	}
+}
+
+// convertHTMLBlock converts a raw HTML block, keeping its source lines exactly
+func (p *GoldmarkParser) convertHTMLBlock(n ast.Node, source []byte) Node {
+	html := n.(*ast.HTMLBlock)
+	var buf bytes.Buffer
+
+	for i := 0; i < html.Lines().Len(); i++ {
+		line := html.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	if html.HasClosure() {
+		buf.Write(html.ClosureLine.Value(source))
+	}
+
+	return &HTMLBlock{
+		Content:  buf.String(),
+		HTMLType: int(html.HTMLBlockType),
 	}
-	return item
 }
 
 // convertCodeBlock converts a code block node
 func (p *GoldmarkParser) convertCodeBlock(n ast.Node, source []byte) Node {
 	code := &CodeBlock{
-		Content: p.extractText(n, source),
+		Content: p.extractCodeBlockText(n, source),
 		Fenced:  n.Kind() == ast.KindFencedCodeBlock,
 		Fence:   "```",
 	}
@@ -167,14 +490,21 @@ func (p *GoldmarkParser) extractCodeBlockInfo(n ast.Node, source []byte, code *C
 
 // convertText converts a text/string node
 func (p *GoldmarkParser) convertText(n ast.Node, source []byte) Node {
+	var content string
+	switch text := n.(type) {
+	case *ast.Text:
+		content = string(text.Segment.Value(source))
+	case *ast.String:
+		content = string(text.Value)
+	}
 	return &Text{
-		Content: p.extractText(n, source),
+		Content: content,
 	}
 }
 
 // convertGenericNode converts other node types to text
 func (p *GoldmarkParser) convertGenericNode(n ast.Node, source []byte) Node {
-	text := p.extractText(n, source)
+	text := strings.TrimSpace(PlainText(p.convertInlines(n, source)))
 	if text != "" {
 		return &Text{
 			Content: text,
@@ -183,56 +513,42 @@ func (p *GoldmarkParser) convertGenericNode(n ast.Node, source []byte) Node {
 	return nil
 }
 
-// getListMarker determines the list marker from a goldmark list
+// getListMarker determines the list marker from a goldmark list: the
+// bullet character, or the delimiter after the numbers of an ordered list
 func (p *GoldmarkParser) getListMarker(list *ast.List) string {
-	if list.IsOrdered() {
-		return "."
-	}
-	return "-" // Default bullet
+	return string(list.Marker)
 }
 
-// getListItemMarker determines the list item marker
-func (p *GoldmarkParser) getListItemMarker(item *ast.ListItem) string {
-	// Check if this is part of an ordered list
-	if parent := item.Parent(); parent != nil && parent.Kind() == ast.KindList {
-		list := parent.(*ast.List)
-		if list.IsOrdered() {
-			// For ordered lists, we'll let the formatter handle the numbering
-			return "1."
-		}
+// orderedItemNumber returns the number an ordered list item was written
+// with. Items without content have no source range to read it from and fall
+// back to their number counting from the start of the list.
+func orderedItemNumber(item *ast.ListItem, source []byte, fallback int) string {
+	span, ok := nodeSpan(item, source)
+	if !ok {
+		return strconv.Itoa(fallback)
 	}
-	return "-" // Default bullet for unordered lists
-}
 
-// extractText extracts the text content from a goldmark AST node
-func (p *GoldmarkParser) extractText(n ast.Node, source []byte) string {
-	switch n.Kind() {
-	case ast.KindText, ast.KindString:
-		return p.extractSimpleText(n, source)
-	case ast.KindFencedCodeBlock, ast.KindCodeBlock:
-		return p.extractCodeBlockText(n, source)
-	case ast.KindListItem:
-		return p.extractListItemText(n, source)
-	case ast.KindList:
-		return ""
-	case ast.KindParagraph:
-		return p.extractParagraphText(n, source)
-	default:
-		return p.extractGenericText(n, source)
+	end := span.start
+	for end < len(source) && source[end] >= '0' && source[end] <= '9' {
+		end++
+	}
+	if end == span.start {
+		return strconv.Itoa(fallback)
 	}
+	return string(source[span.start:end])
 }
 
-// extractSimpleText extracts text from simple text/string nodes
-func (p *GoldmarkParser) extractSimpleText(n ast.Node, source []byte) string {
-	switch n.Kind() {
-	case ast.KindText:
-		text := n.(*ast.Text)
-		return string(text.Segment.Value(source))
-	case ast.KindString:
-		str := n.(*ast.String)
-		return string(str.Value)
+// getListItemMarker determines the list item marker: the bullet, or the
+// number and delimiter of an ordered list item
+func (p *GoldmarkParser) getListItemMarker(item *ast.ListItem, source []byte, number int) string {
+	list, ok := item.Parent().(*ast.List)
+	if !ok {
+		return "-" // Default bullet
+	}
+	if !list.IsOrdered() {
+		return string(list.Marker)
 	}
-	return ""
+	return orderedItemNumber(item, source, number) + string(list.Marker)
 }
 
 // extractCodeBlockText extracts text from code block nodes
@@ -256,145 +572,6 @@ func (p *GoldmarkParser) extractCodeBlockText(n ast.Node, source []byte) string
 	return buf.String()
 }
 
-// extractListItemText extracts text from list item nodes
-func (p *GoldmarkParser) extractListItemText(n ast.Node, source []byte) string {
-	var buf bytes.Buffer
-
-	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-		if child.Kind() != ast.KindList {
-			var childText string
-			if child.Kind() == ast.KindParagraph {
-				// Use paragraph text extraction to preserve inline formatting
-				childText = p.extractParagraphText(child, source)
-			} else {
-				// For all other nodes, try to extract with inline formatting
-				childText = p.extractWithInlineFormatting(child, source)
-			}
-			if childText != "" {
-				if buf.Len() > 0 {
-					buf.WriteString(" ")
-				}
-				buf.WriteString(childText)
-			}
-		}
-	}
-	return strings.TrimSpace(buf.String())
-}
-
-// extractWithInlineFormatting extracts text preserving inline formatting
-func (p *GoldmarkParser) extractWithInlineFormatting(n ast.Node, source []byte) string {
-	var buf bytes.Buffer
-
-	switch n.Kind() {
-	case ast.KindText:
-		text := n.(*ast.Text)
-		buf.Write(text.Segment.Value(source))
-	case ast.KindEmphasis:
-		p.extractEmphasisText(n, source, &buf)
-	case ast.KindCodeSpan:
-		p.extractCodeSpanText(n, source, &buf)
-	case ast.KindLink:
-		p.extractLinkText(n, source, &buf)
-	default:
-		// For container nodes, process children with inline formatting
-		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-			childText := p.extractWithInlineFormatting(child, source)
-			buf.WriteString(childText)
-		}
-	}
-
-	return buf.String()
-}
-
-// extractParagraphText extracts text from paragraph nodes preserving inline formatting
-func (p *GoldmarkParser) extractParagraphText(n ast.Node, source []byte) string {
-	var buf bytes.Buffer
-
-	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-		switch child.Kind() {
-		case ast.KindText:
-			childText := p.extractText(child, source)
-			buf.WriteString(childText)
-		case ast.KindEmphasis:
-			p.extractEmphasisText(child, source, &buf)
-		case ast.KindCodeSpan:
-			p.extractCodeSpanText(child, source, &buf)
-		case ast.KindLink:
-			p.extractLinkText(child, source, &buf)
-		default:
-			childText := p.extractText(child, source)
-			buf.WriteString(childText)
-		}
-	}
-	return strings.TrimSpace(buf.String())
-}
-
-// extractEmphasisText extracts text from emphasis nodes with markers
-func (p *GoldmarkParser) extractEmphasisText(n ast.Node, source []byte, buf *bytes.Buffer) {
-	emph := n.(*ast.Emphasis)
-	marker := "*"
-	if emph.Level == StrongEmphasisLevel {
-		marker = "**"
-	}
-	buf.WriteString(marker)
-	buf.WriteString(p.extractTextRecursive(n, source))
-	buf.WriteString(marker)
-}
-
-// extractCodeSpanText extracts text from inline code with backticks
-func (p *GoldmarkParser) extractCodeSpanText(n ast.Node, source []byte, buf *bytes.Buffer) {
-	buf.WriteString("`")
-	buf.WriteString(p.extractTextRecursive(n, source))
-	buf.WriteString("`")
-}
-
-// extractLinkText extracts text from link nodes with markdown syntax
-func (p *GoldmarkParser) extractLinkText(n ast.Node, source []byte, buf *bytes.Buffer) {
-	link := n.(*ast.Link)
-	buf.WriteString("[")
-	buf.WriteString(p.extractTextRecursive(n, source))
-	buf.WriteString("](")
-	buf.Write(link.Destination)
-	buf.WriteString(")")
-}
-
-// extractGenericText extracts text from other container nodes
-func (p *GoldmarkParser) extractGenericText(n ast.Node, source []byte) string {
-	var buf bytes.Buffer
-
-	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-		if child.Kind() == ast.KindText || child.Kind() == ast.KindString {
-			childText := p.extractText(child, source)
-			buf.WriteString(childText)
-		}
-	}
-	return strings.TrimSpace(buf.String())
-}
-
-// extractTextRecursive extracts text content recursively from all children
-func (p *GoldmarkParser) extractTextRecursive(n ast.Node, source []byte) string {
-	var buf bytes.Buffer
-
-	switch n.Kind() {
-	case ast.KindText:
-		text := n.(*ast.Text)
-		buf.Write(text.Segment.Value(source))
-		return buf.String()
-	case ast.KindString:
-		str := n.(*ast.String)
-		buf.Write(str.Value)
-		return buf.String()
-	}
-
-	// For container nodes, extract text from all children recursively
-	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
-		childText := p.extractTextRecursive(child, source)
-		buf.WriteString(childText)
-	}
-
-	return strings.TrimSpace(buf.String())
-}
-
 // Validate checks if the parser is properly configured
 func (p *GoldmarkParser) Validate() error {
 	if p.markdown == nil {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
 )
 
 func TestNewGoldmarkParser(t *testing.T) {
@@ -39,8 +41,8 @@ func TestGoldmarkParser_ParseHeading(t *testing.T) {
 			if heading.Level != 1 {
 				t.Errorf("Expected heading level 1, got %d", heading.Level)
 			}
-			if !strings.Contains(heading.Text, "Hello World") {
-				t.Errorf("Expected heading text to contain 'Hello World', got %q", heading.Text)
+			if !strings.Contains(heading.Text(), "Hello World") {
+				t.Errorf("Expected heading text to contain 'Hello World', got %q", heading.Text())
 			}
 		}
 	}
@@ -50,6 +52,150 @@ func TestGoldmarkParser_ParseHeading(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseHeadingStyles(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`Setext One
+==========
+
+Setext Two
+---
+
+## Open ATX
+
+### Closed ATX ###
+
+#### C# ####
+
+> Quoted
+> ===
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []struct {
+		text  string
+		level int
+		style string
+		end   Position
+	}{
+		{"Setext One", 1, HeadingStyleSetext, Position{21, 2, 11}},
+		{"Setext Two", 2, HeadingStyleSetext, Position{37, 5, 4}},
+		{"Open ATX", 2, HeadingStyleATX, Position{50, 7, 12}},
+		{"Closed ATX", 3, HeadingStyleATXClosed, Position{70, 9, 19}},
+		{"C#", 4, HeadingStyleATXClosed, Position{84, 11, 13}},
+	}
+
+	for i, want := range expected {
+		heading, ok := doc.Children[i].(*Heading)
+		if !ok {
+			t.Fatalf("Expected Heading at %d, got %T", i, doc.Children[i])
+		}
+		if heading.Text() != want.text || heading.Level != want.level || heading.Style != want.style {
+			t.Errorf("Expected %q level %d in %s style, got %q level %d in %s style",
+				want.text, want.level, want.style, heading.Text(), heading.Level, heading.Style)
+		}
+		if heading.End() != want.end {
+			t.Errorf("Expected %q to end at %#v, got %#v", want.text, want.end, heading.End())
+		}
+	}
+
+	quote := doc.Children[len(expected)].(*Blockquote)
+	if heading, ok := quote.Children[0].(*Heading); !ok || heading.Style != HeadingStyleSetext {
+		t.Errorf("Expected setext heading in blockquote, got %v", quote.Children[0])
+	}
+}
+
+func TestGoldmarkParser_ParseHeadingAttributes(t *testing.T) {
+	parser := NewGoldmarkParser(WithExtensions(config.ExtensionHeadingAttributes))
+	content := []byte(`## Install {#install .wide .dark data-x=1 title="Two words"}
+
+### Closed ### {.note}
+
+## Plain Heading
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	install := doc.Children[0].(*Heading)
+	if install.Text() != "Install" || install.ID != "install" || install.AutoID != "" {
+		t.Errorf("Expected %q with ID %q, got %q with ID %q and auto ID %q",
+			"Install", "install", install.Text(), install.ID, install.AutoID)
+	}
+	if strings.Join(install.Classes, " ") != "wide dark" {
+		t.Errorf("Expected classes [wide dark], got %v", install.Classes)
+	}
+	expected := []Attribute{{Key: "data-x", Value: "1"}, {Key: "title", Value: "Two words"}}
+	if len(install.Attributes) != len(expected) {
+		t.Fatalf("Expected attributes %v, got %v", expected, install.Attributes)
+	}
+	for i, want := range expected {
+		if install.Attributes[i] != want {
+			t.Errorf("Expected attribute %v, got %v", want, install.Attributes[i])
+		}
+	}
+
+	closed := doc.Children[1].(*Heading)
+	if closed.Text() != "Closed" || closed.Style != HeadingStyleATXClosed || closed.ID != "" ||
+		closed.AutoID != "closed" || len(closed.Classes) != 1 || closed.Classes[0] != "note" {
+		t.Errorf("Expected closed heading with class note and auto ID, got %v with ID %q, auto ID %q and classes %v",
+			closed, closed.ID, closed.AutoID, closed.Classes)
+	}
+
+	plain := doc.Children[2].(*Heading)
+	if plain.ID != "" || plain.AutoID != "plain-heading" {
+		t.Errorf("Expected auto ID %q only, got ID %q and auto ID %q", "plain-heading", plain.ID, plain.AutoID)
+	}
+}
+
+func TestGoldmarkParser_HeadingIDs(t *testing.T) {
+	parser := NewGoldmarkParser(WithExtensions(config.ExtensionHeadingAttributes))
+	content := []byte(`## Café & Things!
+
+## Intro {#intro}
+
+## Intro
+
+> ## Intro
+
+## Use ` + "`go test`" + ` and [docs](http://x)
+
+## 中文 标题
+
+## !!!
+
+# Copyright &copy; 2024
+
+## Logo ![alt](x.png) here
+
+## Use \*args and \&amp;
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// The anchors GitHub gives these headings
+	expected := []string{"café--things", "", "intro-1", "intro-2", "use-go-test-and-docs", "中文-标题", "",
+		"copyright--2024", "logo--here", "use-args-and-amp"}
+	var ids []string
+	Inspect(doc, func(node Node) bool {
+		if heading, ok := node.(*Heading); ok {
+			ids = append(ids, heading.AutoID)
+		}
+		return true
+	})
+	if strings.Join(ids, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected auto IDs %q, got %q", expected, ids)
+	}
+}
+
 func TestGoldmarkParser_ParseParagraph(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte("This is a simple paragraph.")
@@ -68,8 +214,8 @@ func TestGoldmarkParser_ParseParagraph(t *testing.T) {
 	for _, child := range doc.Children {
 		if paragraph, ok := child.(*Paragraph); ok {
 			hasParagraph = true
-			if !strings.Contains(paragraph.Text, "simple paragraph") {
-				t.Errorf("Expected paragraph text to contain 'simple paragraph', got %q", paragraph.Text)
+			if !strings.Contains(paragraph.Text(), "simple paragraph") {
+				t.Errorf("Expected paragraph text to contain 'simple paragraph', got %q", paragraph.Text())
 			}
 		}
 	}
@@ -116,13 +262,108 @@ func TestGoldmarkParser_ParseList(t *testing.T) {
 	expectedItems := []string{"Item 1", "Item 2", "Item 3"}
 	for i, item := range list.Items {
 		if i < len(expectedItems) {
-			if !strings.Contains(item.Text, expectedItems[i]) {
-				t.Errorf("Expected item %d to contain %q, got %q", i, expectedItems[i], item.Text)
+			if !strings.Contains(item.Text(), expectedItems[i]) {
+				t.Errorf("Expected item %d to contain %q, got %q", i, expectedItems[i], item.Text())
 			}
 		}
 	}
 }
 
+func TestGoldmarkParser_ParseTaskList(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`- [ ] Open
+- [X] Done
+- [x]   Also done
+- Not [x] a task
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	list, ok := doc.Children[0].(*List)
+	if !ok {
+		t.Fatalf("Expected List, got %T", doc.Children[0])
+	}
+
+	expected := []struct {
+		text string
+		task TaskState
+	}{
+		{"Open", TaskUnchecked},
+		{"Done", TaskChecked},
+		{"Also done", TaskChecked},
+		{"Not [x] a task", TaskNone},
+	}
+
+	if len(list.Items) != len(expected) {
+		t.Fatalf("Expected %d list items, got %d", len(expected), len(list.Items))
+	}
+	for i, want := range expected {
+		item := list.Items[i]
+		if item.Text() != want.text || item.Task != want.task {
+			t.Errorf("Expected item %d to be %q (%s), got %q (%s)", i, want.text, want.task, item.Text(), item.Task)
+		}
+	}
+}
+
+func TestGoldmarkParser_ParseListItemBlocks(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`- First paragraph.
+
+  Second paragraph.
+
+  ` + "```go\n  code\n  ```" + `
+
+  > Quoted
+
+- Tight item
+  - Nested
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	list, ok := doc.Children[0].(*List)
+	if !ok {
+		t.Fatalf("Expected List, got %T", doc.Children[0])
+	}
+	if list.Tight {
+		t.Error("Expected a loose list")
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("Expected 2 list items, got %d", len(list.Items))
+	}
+
+	item := list.Items[0]
+	if item.Text() != "First paragraph." {
+		t.Errorf("Expected item text %q, got %q", "First paragraph.", item.Text())
+	}
+	expected := []NodeType{NodeParagraph, NodeCodeBlock, NodeBlockquote}
+	if len(item.Children) != len(expected) {
+		t.Fatalf("Expected %d child blocks, got %v", len(expected), item.Children)
+	}
+	for i, want := range expected {
+		if item.Children[i].Type() != want {
+			t.Errorf("Expected child %d to be %s, got %s", i, NodeTypeString(want), item.Children[i])
+		}
+	}
+	if code := item.Children[1].(*CodeBlock); code.Content != "code\n" || code.Language != "go" {
+		t.Errorf("Expected go code block with %q, got %v", "code\n", code)
+	}
+
+	nested := list.Items[1]
+	if nested.Text() != "Tight item" || len(nested.Children) != 1 || nested.Children[0].Type() != NodeList {
+		t.Errorf("Expected item with a nested list, got %v with %v", nested, nested.Children)
+	}
+	if !nested.Children[0].(*List).Tight {
+		t.Error("Expected the nested list to be tight")
+	}
+}
+
 func TestGoldmarkParser_ParseOrderedList(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte(`
@@ -158,6 +399,37 @@ func TestGoldmarkParser_ParseOrderedList(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseOrderedListStart(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`5) Five
+7) Seven
+9) Nine
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	list, ok := doc.Children[0].(*List)
+	if !ok {
+		t.Fatalf("Expected List, got %T", doc.Children[0])
+	}
+	if list.Start != 5 || list.Marker != ")" {
+		t.Errorf("Expected list starting at 5 with delimiter ')', got start %d and %q", list.Start, list.Marker)
+	}
+
+	expected := []string{"5)", "7)", "9)"}
+	if len(list.Items) != len(expected) {
+		t.Fatalf("Expected %d list items, got %d", len(expected), len(list.Items))
+	}
+	for i, want := range expected {
+		if list.Items[i].Marker != want {
+			t.Errorf("Expected item %d marker %q, got %q", i, want, list.Items[i].Marker)
+		}
+	}
+}
+
 func TestGoldmarkParser_ParseCodeBlock(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte("```go\nfunc main() {\n    fmt.Println(\"Hello\")\n}\n```")
@@ -193,6 +465,843 @@ func TestGoldmarkParser_ParseCodeBlock(t *testing.T) {
 	}
 }
 
+func TestGoldmarkParser_ParseBlockquote(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`> Quoted paragraph.
+>
+> - item one
+> - item two
+>
+> > Nested quote
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected 1 top-level node, got %d", len(doc.Children))
+	}
+
+	quote, ok := doc.Children[0].(*Blockquote)
+	if !ok {
+		t.Fatalf("Expected Blockquote, got %T", doc.Children[0])
+	}
+
+	if len(quote.Children) != 3 {
+		t.Fatalf("Expected 3 blockquote children, got %d", len(quote.Children))
+	}
+
+	if paragraph, ok := quote.Children[0].(*Paragraph); !ok || paragraph.Text() != "Quoted paragraph." {
+		t.Errorf("Expected first child to be the quoted paragraph, got %v", quote.Children[0])
+	}
+
+	if list, ok := quote.Children[1].(*List); !ok || len(list.Items) != 2 {
+		t.Errorf("Expected second child to be a list with 2 items, got %v", quote.Children[1])
+	}
+
+	nested, ok := quote.Children[2].(*Blockquote)
+	if !ok {
+		t.Fatalf("Expected nested Blockquote, got %T", quote.Children[2])
+	}
+	if len(nested.Children) != 1 {
+		t.Errorf("Expected nested blockquote to have 1 child, got %d", len(nested.Children))
+	}
+}
+
+func TestGoldmarkParser_ParseTable(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`| Name | Value | Notes |
+|:-----|------:|:-----:|
+| a    | 1     | **bold** |
+| b    | 2     |
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	table, ok := doc.Children[0].(*Table)
+	if !ok {
+		t.Fatalf("Expected Table, got %T", doc.Children[0])
+	}
+
+	expectedAlignments := []Alignment{AlignLeft, AlignRight, AlignCenter}
+	if len(table.Alignments) != len(expectedAlignments) {
+		t.Fatalf("Expected %d alignments, got %d", len(expectedAlignments), len(table.Alignments))
+	}
+	for i, alignment := range expectedAlignments {
+		if table.Alignments[i] != alignment {
+			t.Errorf("Expected column %d alignment %s, got %s", i, alignment, table.Alignments[i])
+		}
+	}
+
+	if table.Header == nil || len(table.Header.Cells) != 3 {
+		t.Fatalf("Expected header with 3 cells, got %v", table.Header)
+	}
+	if table.Header.Cells[0].Text() != "Name" {
+		t.Errorf("Expected first header cell 'Name', got %q", table.Header.Cells[0].Text())
+	}
+
+	if len(table.Rows) != 2 {
+		t.Fatalf("Expected 2 body rows, got %d", len(table.Rows))
+	}
+	if cell := table.Rows[0].Cells[2]; len(cell.Inlines) != 1 || cell.Inlines[0].Type() != NodeStrong {
+		t.Errorf("Expected inline formatting to be kept, got %v", cell.Inlines)
+	}
+}
+
+func TestGoldmarkParser_ParseThematicBreak(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte("First section\n\n***\n\nSecond section\n\n___\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	breaks := FindNodes(doc, NodeThematicBreak)
+	if len(breaks) != 2 {
+		t.Fatalf("Expected 2 thematic breaks, got %d", len(breaks))
+	}
+
+	if len(doc.Children) != 4 {
+		t.Errorf("Expected 4 top-level nodes, got %d", len(doc.Children))
+	}
+}
+
+func TestGoldmarkParser_ParseHTMLBlock(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`<p align="center">
+  <img src="logo.png">
+</p>
+
+<!-- a comment
+
+spanning blank lines -->
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 2 {
+		t.Fatalf("Expected 2 HTML blocks, got %d nodes", len(doc.Children))
+	}
+
+	expected := []struct {
+		content  string
+		htmlType int
+	}{
+		{"<p align=\"center\">\n  <img src=\"logo.png\">\n</p>\n", 6},
+		{"<!-- a comment\n\nspanning blank lines -->\n", 2},
+	}
+
+	for i, want := range expected {
+		html, ok := doc.Children[i].(*HTMLBlock)
+		if !ok {
+			t.Fatalf("Expected HTMLBlock at %d, got %T", i, doc.Children[i])
+		}
+		if html.Content != want.content {
+			t.Errorf("Expected content %q, got %q", want.content, html.Content)
+		}
+		if html.HTMLType != want.htmlType {
+			t.Errorf("Expected HTML block type %d, got %d", want.htmlType, html.HTMLType)
+		}
+	}
+}
+
+func TestGoldmarkParser_ParseFootnotes(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`Text with a note[^b] and a missing one[^zz].
+
+[^b]: Bee note.
+
+    Second paragraph.
+
+[^unused]: Nobody cites me.
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected paragraph and 2 footnote definitions, got %d nodes", len(doc.Children))
+	}
+
+	para, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+	}
+	if len(para.Inlines) != 3 {
+		t.Fatalf("Expected text, reference and text, got %v", para.Inlines)
+	}
+	if ref, ok := para.Inlines[1].(*FootnoteReference); !ok || ref.Label != "b" {
+		t.Errorf("Expected reference to [^b], got %v", para.Inlines[1])
+	}
+	if text, ok := para.Inlines[2].(*Text); !ok || text.Content != " and a missing one[^zz]." {
+		t.Errorf("Expected undefined reference to stay text, got %v", para.Inlines[2])
+	}
+
+	expected := []struct {
+		label    string
+		children int
+	}{
+		{"b", 2},
+		{"unused", 1},
+	}
+
+	for i, want := range expected {
+		def, ok := doc.Children[i+1].(*FootnoteDefinition)
+		if !ok {
+			t.Fatalf("Expected FootnoteDefinition at %d, got %T", i+1, doc.Children[i+1])
+		}
+		if def.Label != want.label {
+			t.Errorf("Expected label %q, got %q", want.label, def.Label)
+		}
+		if len(def.Children) != want.children {
+			t.Errorf("Expected %d children in [^%s], got %d", want.children, want.label, len(def.Children))
+		}
+	}
+}
+
+func TestGoldmarkParser_ParseNestedFootnotes(t *testing.T) {
+	content := []byte(`Text[^a] and[^b].
+
+- item
+
+  [^a]: In a list item.
+
+> [^b]: In a quote.
+`)
+
+	doc, err := NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected paragraph, list and blockquote, got %v", doc.Children)
+	}
+
+	item := doc.Children[1].(*List).Items[0]
+	if len(item.Children) != 1 {
+		t.Fatalf("Expected the definition in the list item, got %v", item.Children)
+	}
+	if def, ok := item.Children[0].(*FootnoteDefinition); !ok || def.Label != "a" {
+		t.Errorf("Expected [^a] in the list item, got %v", item.Children[0])
+	}
+	if def := item.Children[0]; def.Pos().Line != 5 || def.Pos().Column != 3 {
+		t.Errorf("Expected [^a] to start at 5:3, got %v", def.Pos())
+	}
+
+	quote := doc.Children[2].(*Blockquote)
+	if len(quote.Children) != 1 {
+		t.Fatalf("Expected the definition in the blockquote, got %v", quote.Children)
+	}
+	if def, ok := quote.Children[0].(*FootnoteDefinition); !ok || def.Label != "b" {
+		t.Errorf("Expected [^b] in the blockquote, got %v", quote.Children[0])
+	}
+
+	refs := FindNodes(doc, NodeFootnoteReference)
+	if len(refs) != 2 || refs[0].(*FootnoteReference).Label != "a" || refs[1].(*FootnoteReference).Label != "b" {
+		t.Errorf("Expected references to [^a] and [^b], got %v", refs)
+	}
+}
+
+func TestGoldmarkParser_ParseLinkReferences(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`See [the docs][Docs], [collapsed][] and [shortcut].
+
+[docs]: https://example.com/docs "Docs Title"
+[collapsed]: <https://example.com/a b>
+[shortcut]:
+  /short
+  (Paren title)
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Children) != 4 {
+		t.Fatalf("Expected paragraph and 3 definitions, got %d nodes", len(doc.Children))
+	}
+
+	para, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+	}
+	links := []struct {
+		text      string
+		reference ReferenceStyle
+		label     string
+	}{
+		{"the docs", ReferenceFull, "Docs"},
+		{"collapsed", ReferenceCollapsed, "collapsed"},
+		{"shortcut", ReferenceShortcut, "shortcut"},
+	}
+	var found []*Link
+	for _, inline := range para.Inlines {
+		if link, ok := inline.(*Link); ok {
+			found = append(found, link)
+		}
+	}
+	if len(found) != len(links) {
+		t.Fatalf("Expected %d links, got %v", len(links), para.Inlines)
+	}
+	for i, want := range links {
+		link := found[i]
+		if PlainText(link.Children) != want.text || link.Reference != want.reference || link.Label != want.label {
+			t.Errorf("Expected link %q with reference %d and label %q, got %v (reference %d, label %q)",
+				want.text, want.reference, want.label, link, link.Reference, link.Label)
+		}
+	}
+
+	expected := []LinkReferenceDefinition{
+		{Label: "docs", Destination: "https://example.com/docs", Title: "Docs Title"},
+		{Label: "collapsed", Destination: "https://example.com/a b"},
+		{Label: "shortcut", Destination: "/short", Title: "Paren title"},
+	}
+
+	for i, want := range expected {
+		def, ok := doc.Children[i+1].(*LinkReferenceDefinition)
+		if !ok {
+			t.Fatalf("Expected LinkReferenceDefinition at %d, got %T", i+1, doc.Children[i+1])
+		}
+		if def.Label != want.Label || def.Destination != want.Destination || def.Title != want.Title {
+			t.Errorf("Expected %v, got %v", &want, def)
+		}
+	}
+}
+
+func TestGoldmarkParser_ParseImages(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`# Logo ![logo](logo.png)
+
+See ![diagram *arch*](img/diagram.png "Architecture") and ![spaced](<my img.png>).
+
+[![ci](badge.svg)](https://ci.example) [![cov][cov-badge]][cov]
+
+- ![icon](icon.png)
+
+[cov-badge]: /cov.svg
+[cov]: /cov
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	heading, ok := doc.Children[0].(*Heading)
+	if !ok || len(heading.Inlines) != 2 || heading.Inlines[1].Type() != NodeImage {
+		t.Errorf("Expected heading to keep the image, got %v", doc.Children[0])
+	}
+
+	para, ok := doc.Children[1].(*Paragraph)
+	if !ok || len(para.Inlines) != 5 {
+		t.Fatalf("Expected paragraph with two images, got %v", doc.Children[1])
+	}
+	diagram, ok := para.Inlines[1].(*Image)
+	if !ok || diagram.Destination != "img/diagram.png" || diagram.Title != "Architecture" {
+		t.Errorf("Expected diagram image, got %v", para.Inlines[1])
+	} else if len(diagram.Children) != 2 || diagram.Children[1].Type() != NodeEmphasis {
+		t.Errorf("Expected alt text to keep its emphasis, got %v", diagram.Children)
+	}
+	if spaced, ok := para.Inlines[3].(*Image); !ok || spaced.Destination != "my img.png" {
+		t.Errorf("Expected image with spaced destination, got %v", para.Inlines[3])
+	}
+
+	para, ok = doc.Children[2].(*Paragraph)
+	if !ok || len(para.Inlines) != 3 {
+		t.Fatalf("Expected paragraph with two linked images, got %v", doc.Children[2])
+	}
+	badge, ok := para.Inlines[2].(*Link)
+	if !ok || badge.Reference != ReferenceFull || badge.Label != "cov" || len(badge.Children) != 1 {
+		t.Fatalf("Expected reference link around an image, got %v", para.Inlines[2])
+	}
+	if image, ok := badge.Children[0].(*Image); !ok || image.Reference != ReferenceFull || image.Label != "cov-badge" {
+		t.Errorf("Expected reference image inside the link, got %v", badge.Children[0])
+	}
+
+	list, ok := doc.Children[3].(*List)
+	if !ok || len(list.Items) != 1 || len(list.Items[0].Inlines) != 1 || list.Items[0].Inlines[0].Type() != NodeImage {
+		t.Errorf("Expected list item to keep the image, got %v", doc.Children[3])
+	}
+}
+
+func TestGoldmarkParser_ParseInlines(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte("Some *em* and _em_, **strong** and __strong__, ~~gone~~,\\\n" +
+		"`a\nb` <https://example.com> https://bare.example <b>x</b>  \nend\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	para, ok := doc.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected Paragraph, got %T", doc.Children[0])
+	}
+
+	expected := []Node{
+		&Text{Content: "Some "},
+		&Emphasis{Marker: "*", Children: []Node{&Text{Content: "em"}}},
+		&Text{Content: " and "},
+		&Emphasis{Marker: "_", Children: []Node{&Text{Content: "em"}}},
+		&Text{Content: ", "},
+		&Strong{Marker: "**", Children: []Node{&Text{Content: "strong"}}},
+		&Text{Content: " and "},
+		&Strong{Marker: "__", Children: []Node{&Text{Content: "strong"}}},
+		&Text{Content: ", "},
+		&Strikethrough{Children: []Node{&Text{Content: "gone"}}},
+		&Text{Content: ","},
+		&HardBreak{Marker: "\\"},
+		&CodeSpan{Content: "a b"},
+		&Text{Content: " "},
+		&Autolink{URL: "https://example.com"},
+		&Text{Content: " "},
+		&Autolink{URL: "https://bare.example", Bare: true},
+		&Text{Content: " "},
+		&RawHTML{Content: "<b>"},
+		&Text{Content: "x"},
+		&RawHTML{Content: "</b>"},
+		&HardBreak{Marker: "  "},
+		&Text{Content: "end"},
+	}
+
+	if len(para.Inlines) != len(expected) {
+		t.Fatalf("Expected %d inline nodes, got %d: %v", len(expected), len(para.Inlines), para.Inlines)
+	}
+	for i, want := range expected {
+		if got := para.Inlines[i].String(); got != want.String() {
+			t.Errorf("Inline %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	if text := para.Text(); text != "Some em and em, strong and strong, gone,\na b https://example.com https://bare.example x\nend" {
+		t.Errorf("Unexpected plain text %q", text)
+	}
+}
+
+func TestGoldmarkParser_ParseAutolinks(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte("<https://a.example> <me@example.com> www.example.com/x and me@example.org.\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var autolinks []string
+	for _, node := range FindNodes(doc, NodeAutolink) {
+		autolinks = append(autolinks, node.String())
+	}
+	expected := []string{
+		(&Autolink{URL: "https://a.example"}).String(),
+		(&Autolink{URL: "me@example.com", Email: true}).String(),
+		(&Autolink{URL: "www.example.com/x", Bare: true}).String(),
+		(&Autolink{URL: "me@example.org", Bare: true, Email: true}).String(),
+	}
+	if strings.Join(autolinks, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected autolinks\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(autolinks, "\n"))
+	}
+}
+
+func TestGoldmarkParser_ParseMath(t *testing.T) {
+	parser := NewGoldmarkParser(WithExtensions(config.ExtensionMath))
+	content := []byte("Sum $a_1 * b_2$ and $$x_*$$ across $a\nb$ lines.\n\n" +
+		"Costs $5 or $10, and $ a $ stays.\n" +
+		"$$\n*e* = mc^2\n\n_x_\n$$\n")
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var maths []string
+	for _, node := range FindNodes(doc, NodeInlineMath) {
+		maths = append(maths, node.(*InlineMath).Content)
+	}
+	expected := []string{"$a_1 * b_2$", "$$x_*$$", "$a\nb$"}
+	if strings.Join(maths, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected inline math %q, got %q", expected, maths)
+	}
+	if FindFirstNode(doc, NodeEmphasis) != nil {
+		t.Error("Expected no emphasis inside math")
+	}
+
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected two paragraphs and a math block, got %v", doc.Children)
+	}
+	block, ok := doc.Children[2].(*MathBlock)
+	if !ok {
+		t.Fatalf("Expected math block, got %T", doc.Children[2])
+	}
+	if block.Content != "$$\n*e* = mc^2\n\n_x_\n$$" {
+		t.Errorf("Expected math block source, got %q", block.Content)
+	}
+}
+
+func TestGoldmarkParser_ParseAdmonitions(t *testing.T) {
+	parser := NewGoldmarkParser(WithExtensions(config.ExtensionAlerts, config.ExtensionAdmonitions))
+	content := []byte(`> [!note]
+> Body text.
+
+> [!TIP]
+> - item
+
+> [!NOTE]: not an alert
+
+> [!NOTE] Custom title
+> not an alert either
+
+!!! warning "Careful"
+    First.
+
+    Second.
+
+???+ danger custom ""
+    Collapsible.
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []Admonition{
+		{Syntax: AdmonitionSyntaxGitHub, Kind: "note"},
+		{Syntax: AdmonitionSyntaxGitHub, Kind: "TIP"},
+		{},
+		{},
+		{Syntax: AdmonitionSyntaxMkDocs, Marker: "!!!", Kind: "warning", Title: "Careful"},
+		{Syntax: AdmonitionSyntaxMkDocs, Marker: "???+", Kind: "danger custom", HideTitle: true},
+	}
+	if len(doc.Children) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %v", len(expected), doc.Children)
+	}
+	for i, want := range expected {
+		admonition, ok := doc.Children[i].(*Admonition)
+		if want.Syntax == "" {
+			if ok {
+				t.Errorf("Expected blockquote at %d, got %v", i, admonition)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("Expected Admonition at %d, got %T", i, doc.Children[i])
+		}
+		if admonition.Syntax != want.Syntax || admonition.Marker != want.Marker || admonition.Kind != want.Kind ||
+			admonition.Title != want.Title || admonition.HideTitle != want.HideTitle {
+			t.Errorf("Expected %v, got %v with marker %q", &want, admonition, admonition.Marker)
+		}
+	}
+
+	note := doc.Children[0].(*Admonition)
+	if len(note.Children) != 1 || note.Children[0].(*Paragraph).Text() != "Body text." {
+		t.Errorf("Expected the marker line taken out of the body, got %v", note.Children)
+	}
+	if note.Children[0].Pos().Line != 2 {
+		t.Errorf("Expected body to start on line 2, got %d", note.Children[0].Pos().Line)
+	}
+	if tip := doc.Children[1].(*Admonition); len(tip.Children) != 1 || tip.Children[0].Type() != NodeList {
+		t.Errorf("Expected a list as the only block of the tip, got %v", tip.Children)
+	}
+	if warning := doc.Children[4].(*Admonition); len(warning.Children) != 2 || warning.Pos().Line != 12 {
+		t.Errorf("Expected two paragraphs in the warning starting on line 12, got %v at line %d",
+			warning.Children, warning.Pos().Line)
+	}
+
+	// MkDocs admonitions are not part of GFM
+	doc, err = NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if admonitions := FindNodes(doc, NodeAdmonition); len(admonitions) != 2 {
+		t.Errorf("Expected only the GitHub alerts in GFM, got %v", admonitions)
+	}
+}
+
+func TestGoldmarkParser_ParseDefinitionLists(t *testing.T) {
+	content := []byte(`Apple
+Pomme
+:   A *fruit*.
+: A company.
+
+Orange
+
+: First paragraph.
+
+  Second paragraph.
+`)
+
+	doc, err := NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if FindFirstNode(doc, NodeDefinitionList) != nil {
+		t.Error("Expected no definition list without the option")
+	}
+
+	doc, err = NewGoldmarkParser(WithDefinitionLists()).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 1 {
+		t.Fatalf("Expected a single definition list, got %v", doc.Children)
+	}
+	list, ok := doc.Children[0].(*DefinitionList)
+	if !ok {
+		t.Fatalf("Expected DefinitionList, got %T", doc.Children[0])
+	}
+
+	var items []string
+	for _, child := range list.Children {
+		switch n := child.(type) {
+		case *Term:
+			items = append(items, "term "+n.Text())
+		case *Definition:
+			items = append(items, fmt.Sprintf("definition tight=%t blocks=%d", n.Tight, len(n.Children)))
+		}
+	}
+	expected := []string{
+		"term Apple",
+		"term Pomme",
+		"definition tight=true blocks=1",
+		"definition tight=true blocks=1",
+		"term Orange",
+		"definition tight=false blocks=2",
+	}
+	if strings.Join(items, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected items\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(items, "\n"))
+	}
+
+	first := list.Children[2].(*Definition)
+	if first.Pos() != (Position{Offset: 12, Line: 3, Column: 1}) {
+		t.Errorf("Expected the definition to start at its marker, got %#v", first.Pos())
+	}
+	if FindFirstNode(doc, NodeEmphasis) == nil {
+		t.Error("Expected inline content in definitions")
+	}
+}
+
+func TestGoldmarkParser_ParseMDX(t *testing.T) {
+	content := []byte(`import Tabs from '@theme/Tabs';
+export const answer = 42;
+
+<Tabs
+  groupId="os">
+  <TabItem value="a">
+
+  Some *text* with {answer} and <Badge label="x y" />.
+
+  ` + "```" + `
+  </TabItem>
+  ` + "```" + `
+
+  </TabItem>
+</Tabs>
+
+{/* don't format */}
+`)
+
+	doc, err := NewGoldmarkParser(WithMDX()).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected 3 blocks, got %v", doc.Children)
+	}
+
+	esm, ok := doc.Children[0].(*ESM)
+	if !ok {
+		t.Fatalf("Expected ESM, got %T", doc.Children[0])
+	}
+	if esm.Content != "import Tabs from '@theme/Tabs';\nexport const answer = 42;" {
+		t.Errorf("Unexpected ESM content %q", esm.Content)
+	}
+
+	tabs, ok := doc.Children[1].(*JSX)
+	if !ok {
+		t.Fatalf("Expected JSX, got %T", doc.Children[1])
+	}
+	if tabs.Opening != "<Tabs\n  groupId=\"os\">" || tabs.Closing != "</Tabs>" {
+		t.Errorf("Unexpected tags %q and %q", tabs.Opening, tabs.Closing)
+	}
+	if tabs.Indent != 2 || !tabs.Tight || len(tabs.Children) != 1 {
+		t.Fatalf("Unexpected element %v with indent %d and children %v", tabs, tabs.Indent, tabs.Children)
+	}
+	if tabs.End().Line != 15 {
+		t.Errorf("Expected the element to end on its closing tag line, got %v", tabs.End())
+	}
+
+	item, ok := tabs.Children[0].(*JSX)
+	if !ok {
+		t.Fatalf("Expected nested JSX, got %T", tabs.Children[0])
+	}
+	if item.Closing != "</TabItem>" || item.Tight || len(item.Children) != 2 {
+		t.Fatalf("Unexpected nested element %v with closing %q", item, item.Closing)
+	}
+	paragraph, ok := item.Children[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Expected a paragraph, got %T", item.Children[0])
+	}
+	var raw []string
+	for _, inline := range paragraph.Inlines {
+		if html, ok := inline.(*RawHTML); ok {
+			raw = append(raw, html.Content)
+		}
+	}
+	if strings.Join(raw, ",") != `{answer},<Badge label="x y" />` {
+		t.Errorf("Expected inline JSX as raw HTML, got %q", raw)
+	}
+	if code, ok := item.Children[1].(*CodeBlock); !ok || code.Content != "</TabItem>\n" {
+		t.Errorf("Expected the closing tag in the code block to stay code, got %v", item.Children[1])
+	}
+
+	expression, ok := doc.Children[2].(*JSX)
+	if !ok || expression.Opening != "{/* don't format */}" || len(expression.Children) != 0 {
+		t.Errorf("Expected a JSX expression, got %v", doc.Children[2])
+	}
+
+	doc, err = NewGoldmarkParser().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if FindFirstNode(doc, NodeJSX) != nil || FindFirstNode(doc, NodeESM) != nil {
+		t.Error("Expected no MDX nodes without the option")
+	}
+}
+
+func TestGoldmarkParser_ParseDialects(t *testing.T) {
+	content := []byte(`Some ~~struck~~ text at https://example.com
+
+| a | b |
+|---|---|
+| 1 | 2 |
+
+> [!NOTE]
+> Read this.
+`)
+
+	doc, err := NewGoldmarkParser(WithExtensions()).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected 3 blocks, got %v", doc.Children)
+	}
+	if _, ok := doc.Children[1].(*Paragraph); !ok {
+		t.Errorf("Expected the table to stay a paragraph in CommonMark, got %v", doc.Children[1])
+	}
+	if _, ok := doc.Children[2].(*Blockquote); !ok {
+		t.Errorf("Expected the alert to stay a blockquote in CommonMark, got %T", doc.Children[2])
+	}
+	for _, nodeType := range []NodeType{NodeStrikethrough, NodeAutolink, NodeTable, NodeAdmonition} {
+		if FindFirstNode(doc, nodeType) != nil {
+			t.Errorf("Expected no %v in CommonMark", nodeType)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Markdown.Dialect = config.Dialect{Extensions: []string{config.ExtensionTables}}
+	doc, err = NewGoldmarkParserFromConfig(cfg).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if FindFirstNode(doc, NodeTable) == nil {
+		t.Error("Expected a table with the tables extension")
+	}
+	if FindFirstNode(doc, NodeStrikethrough) != nil || FindFirstNode(doc, NodeAdmonition) != nil {
+		t.Error("Expected only the tables extension to be enabled")
+	}
+
+	doc, err = NewGoldmarkParserFromConfig(config.Default()).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, nodeType := range []NodeType{NodeStrikethrough, NodeAutolink, NodeTable, NodeAdmonition} {
+		if FindFirstNode(doc, nodeType) == nil {
+			t.Errorf("Expected %v in GFM", nodeType)
+		}
+	}
+
+	// Math and heading attributes are not part of GFM
+	doc, err = NewGoldmarkParser().Parse([]byte("## Title {#id}\n\n$$\nx\n$$\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if heading := doc.Children[0].(*Heading); heading.ID != "" || heading.Text() != "Title {#id}" {
+		t.Errorf("Expected the braces to stay heading text in GFM, got %q with ID %q", heading.Text(), heading.ID)
+	}
+	if FindFirstNode(doc, NodeMathBlock) != nil {
+		t.Error("Expected no math block in GFM")
+	}
+}
+
+func TestGoldmarkParser_Positions(t *testing.T) {
+	parser := NewGoldmarkParser()
+	content := []byte(`---
+title: Test
+---
+# Title
+
+Some *emphasis* and [a link](https://example.com).
+
+- first
+- second
+
+[ref]: /docs "Docs"
+`)
+
+	doc, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	heading := doc.Children[0].(*Heading)
+	para := doc.Children[1].(*Paragraph)
+	list := doc.Children[2].(*List)
+	definition := doc.Children[3].(*LinkReferenceDefinition)
+
+	tests := []struct {
+		name  string
+		node  Node
+		start Position
+		end   Position
+	}{
+		{"front matter", doc.FrontMatter, Position{0, 1, 1}, Position{19, 3, 4}},
+		{"heading", heading, Position{20, 4, 1}, Position{27, 4, 8}},
+		{"paragraph", para, Position{29, 6, 1}, Position{79, 6, 51}},
+		{"emphasis", para.Inlines[1], Position{34, 6, 6}, Position{44, 6, 16}},
+		{"link", para.Inlines[3], Position{49, 6, 21}, Position{78, 6, 50}},
+		{"list", list, Position{81, 8, 1}, Position{97, 9, 9}},
+		{"second item", list.Items[1], Position{89, 9, 1}, Position{97, 9, 9}},
+		{"definition", definition, Position{99, 11, 1}, Position{118, 11, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.node.Pos() != tt.start || tt.node.End() != tt.end {
+				t.Errorf("Expected %v to span %#v to %#v, got %#v to %#v",
+					tt.node, tt.start, tt.end, tt.node.Pos(), tt.node.End())
+			}
+		})
+	}
+
+	if pos := doc.Pos(); pos != (Position{0, 1, 1}) {
+		t.Errorf("Expected document to start at 1:1, got %+v", pos)
+	}
+}
+
 func TestGoldmarkParser_ParseComplexDocument(t *testing.T) {
 	parser := NewGoldmarkParser()
 	content := []byte(`# Title
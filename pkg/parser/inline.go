@@ -0,0 +1,269 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// emphasisMarkerAttribute is the goldmark node attribute that records the
+	// delimiter character an emphasis was written with
+	emphasisMarkerAttribute = "mdfmt-emphasis-marker"
+	// angleAutolinkAttribute is the goldmark node attribute that marks
+	// autolinks written in angle brackets, as opposed to bare URLs
+	angleAutolinkAttribute = "mdfmt-angle-autolink"
+
+	// backslashHardBreak is the hard line break marker written as a backslash
+	backslashHardBreak = "\\"
+	// spaceHardBreak is the hard line break marker written as trailing spaces
+	spaceHardBreak = "  "
+)
+
+// inlineParsers returns goldmark's default inline parsers with the link,
+// autolink and emphasis parsers wrapped to keep inline markup as written
+func inlineParsers() []util.PrioritizedValue {
+	parsers := gmparser.DefaultInlineParsers()
+	for i, parser := range parsers {
+		switch parser.Value {
+		case gmparser.NewLinkParser():
+			parsers[i].Value = &referenceLinkParser{InlineParser: gmparser.NewLinkParser()}
+		case gmparser.NewAutoLinkParser():
+			parsers[i].Value = &spanParser{&angleAutolinkParser{InlineParser: gmparser.NewAutoLinkParser()}}
+		case gmparser.NewEmphasisParser():
+			parsers[i].Value = emphasisParser{}
+		default:
+			parsers[i].Value = &spanParser{parser.Value.(gmparser.InlineParser)}
+		}
+	}
+	return parsers
+}
+
+// angleAutolinkParser wraps goldmark's autolink parser and marks the
+// autolinks it finds, so that they can be told apart from GFM bare URLs
+type angleAutolinkParser struct {
+	gmparser.InlineParser
+}
+
+// Parse parses an autolink in angle brackets
+func (s *angleAutolinkParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node != nil {
+		node.SetAttributeString(angleAutolinkAttribute, true)
+	}
+	return node
+}
+
+// emphasisParser parses emphasis like goldmark's emphasis parser, but
+// records the delimiter character on the emphasis nodes it creates
+type emphasisParser struct{}
+
+// Trigger returns the emphasis delimiter characters
+func (s emphasisParser) Trigger() []byte {
+	return []byte{'*', '_'}
+}
+
+// Parse scans a delimiter run and pushes it for delimiter processing
+func (s emphasisParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := gmparser.ScanDelimiter(line, before, 1, emphasisDelimiters{marker: line[0]})
+	if node == nil {
+		return nil
+	}
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+// emphasisDelimiters is the delimiter processor for one emphasis character
+type emphasisDelimiters struct {
+	marker byte
+}
+
+// IsDelimiter reports whether b is an emphasis delimiter
+func (p emphasisDelimiters) IsDelimiter(b byte) bool {
+	return b == '*' || b == '_'
+}
+
+// CanOpenCloser reports whether the delimiters can form an emphasis
+func (p emphasisDelimiters) CanOpenCloser(opener, closer *gmparser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+// OnMatch creates an emphasis node that remembers its delimiter
+func (p emphasisDelimiters) OnMatch(consumes int) ast.Node {
+	node := ast.NewEmphasis(consumes)
+	node.SetAttributeString(emphasisMarkerAttribute, string(p.marker))
+	return node
+}
+
+// convertInlines converts the inline children of a goldmark node.
+// Adjacent text is merged into a single Text node.
+func (p *GoldmarkParser) convertInlines(n ast.Node, source []byte) []Node {
+	inlines := make([]Node, 0)
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		inlines = p.appendInline(inlines, child, source)
+	}
+	return inlines
+}
+
+// appendInline converts a goldmark inline node and appends it to inlines
+func (p *GoldmarkParser) appendInline(inlines []Node, n ast.Node, source []byte) []Node {
+	span, _ := nodeSpan(n, source)
+
+	var node Node
+	switch n.Kind() {
+	case ast.KindText:
+		return appendTextLine(inlines, n.(*ast.Text), source)
+	case ast.KindString:
+		return appendText(inlines, string(n.(*ast.String).Value), span)
+	case ast.KindEmphasis:
+		node = p.convertEmphasis(n, source)
+	case ast.KindCodeSpan:
+		node = &CodeSpan{Content: codeSpanContent(n, source)}
+	case kindInlineMath:
+		node = &InlineMath{Content: inlineMathContent(n.(*inlineMathNode), source)}
+	case ast.KindLink:
+		link := n.(*ast.Link)
+		reference, label := linkReference(n)
+		node = &Link{
+			Children:    p.convertInlines(n, source),
+			Destination: string(link.Destination),
+			Title:       string(link.Title),
+			Reference:   reference,
+			Label:       label,
+		}
+	case ast.KindImage:
+		node = p.convertImage(n, source)
+	case ast.KindAutoLink:
+		autolink := n.(*ast.AutoLink)
+		_, angle := n.AttributeString(angleAutolinkAttribute)
+		node = &Autolink{
+			URL:   string(autolink.Label(source)),
+			Bare:  !angle,
+			Email: autolink.AutoLinkType == ast.AutoLinkEmail,
+		}
+	case ast.KindRawHTML:
+		node = &RawHTML{Content: rawHTMLContent(n.(*ast.RawHTML), source)}
+	case extast.KindStrikethrough:
+		node = &Strikethrough{Children: p.convertInlines(n, source)}
+	case extast.KindFootnoteLink:
+		node = p.convertFootnoteLink(n)
+	default:
+		// Unknown inline containers keep their content
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			inlines = p.appendInline(inlines, child, source)
+		}
+		return inlines
+	}
+
+	setSpan(node, span)
+	return append(inlines, node)
+}
+
+// appendTextLine appends a goldmark text node and the line break that may follow it
+func appendTextLine(inlines []Node, text *ast.Text, source []byte) []Node {
+	stop := text.Segment.Stop
+	inlines = appendText(inlines, string(text.Segment.Value(source)), sourceSpan{text.Segment.Start, stop})
+
+	var lineBreak Node
+	switch {
+	case text.HardLineBreak():
+		lineBreak = &HardBreak{Marker: hardBreakMarker(text, source)}
+	case text.SoftLineBreak():
+		lineBreak = &SoftBreak{}
+	default:
+		return inlines
+	}
+
+	// A line break covers the rest of the line, including the line ending
+	setSpan(lineBreak, sourceSpan{stop, min(lineEnd(source, stop)+1, len(source))})
+	return append(inlines, lineBreak)
+}
+
+// appendText appends literal text, merging it into a preceding Text node
+func appendText(inlines []Node, content string, span sourceSpan) []Node {
+	if content == "" {
+		return inlines
+	}
+	if len(inlines) > 0 {
+		if previous, ok := inlines[len(inlines)-1].(*Text); ok {
+			previous.Content += content
+			if previous.End().Offset > 0 && span.end > span.start {
+				previous.SetRange(previous.Pos(), Position{Offset: span.end})
+			}
+			return inlines
+		}
+	}
+
+	text := &Text{Content: content}
+	setSpan(text, span)
+	return append(inlines, text)
+}
+
+// hardBreakMarker returns how the hard line break after text was written.
+// goldmark ends the text segment right before the marker.
+func hardBreakMarker(text *ast.Text, source []byte) string {
+	if stop := text.Segment.Stop; stop < len(source) && source[stop] == '\\' {
+		return backslashHardBreak
+	}
+	return spaceHardBreak
+}
+
+// convertEmphasis converts a goldmark emphasis into Emphasis or Strong
+func (p *GoldmarkParser) convertEmphasis(n ast.Node, source []byte) Node {
+	marker := "*"
+	if value, ok := n.AttributeString(emphasisMarkerAttribute); ok {
+		marker, _ = value.(string)
+	}
+
+	children := p.convertInlines(n, source)
+	if n.(*ast.Emphasis).Level == StrongEmphasisLevel {
+		return &Strong{Marker: strings.Repeat(marker, StrongEmphasisLevel), Children: children}
+	}
+	return &Emphasis{Marker: marker, Children: children}
+}
+
+// convertImage converts an image node, keeping the inline markup of its alt text
+func (p *GoldmarkParser) convertImage(n ast.Node, source []byte) *Image {
+	image := n.(*ast.Image)
+	reference, label := linkReference(n)
+	return &Image{
+		Children:    p.convertInlines(n, source),
+		Destination: string(image.Destination),
+		Title:       string(image.Title),
+		Reference:   reference,
+		Label:       label,
+	}
+}
+
+// codeSpanContent returns the content of a code span with line endings
+// turned into spaces, as CommonMark specifies
+func codeSpanContent(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			sb.Write(c.Segment.Value(source))
+		case *ast.String:
+			sb.Write(c.Value)
+		}
+	}
+	return strings.ReplaceAll(sb.String(), "\n", " ")
+}
+
+// rawHTMLContent returns the source of an inline HTML node
+func rawHTMLContent(n *ast.RawHTML, source []byte) string {
+	var sb strings.Builder
+	for i := 0; i < n.Segments.Len(); i++ {
+		segment := n.Segments.At(i)
+		sb.Write(segment.Value(source))
+	}
+	return sb.String()
+}
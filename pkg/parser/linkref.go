@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// linkReferenceTransformerPriority matches the priority of goldmark's link reference transformer
+	linkReferenceTransformerPriority = 100
+	// referenceAttribute is the goldmark node attribute that records how a
+	// reference link was written: "[label]", "[]" or "" for shortcut links
+	referenceAttribute = "mdfmt-reference"
+	// referenceLabelAttribute is the goldmark node attribute that records the
+	// label of collapsed and shortcut references, which is their link text
+	referenceLabelAttribute = "mdfmt-reference-label"
+)
+
+// kindLinkReferenceBlock is the goldmark node kind for the link reference
+// definitions taken out of a paragraph
+var kindLinkReferenceBlock = ast.NewNodeKind("LinkReferenceBlock")
+
+// linkReferenceBlock holds the source lines of consecutive link reference
+// definitions. goldmark only registers definitions in the parser context, so
+// this block keeps them in the tree at the position they were written.
+type linkReferenceBlock struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.Kind
+func (b *linkReferenceBlock) Kind() ast.NodeKind { return kindLinkReferenceBlock }
+
+// IsRaw implements ast.Node.IsRaw; definitions are not parsed as inline content
+func (b *linkReferenceBlock) IsRaw() bool { return true }
+
+// Dump implements ast.Node.Dump
+func (b *linkReferenceBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(b, source, level, nil, nil)
+}
+
+// linkReferenceTransformer wraps goldmark's link reference paragraph
+// transformer and records the lines it removes in a linkReferenceBlock
+var linkReferenceTransformer gmparser.ParagraphTransformer = &linkReferences{}
+
+type linkReferences struct{}
+
+// Transform extracts link reference definitions from the start of a paragraph
+func (t *linkReferences) Transform(node *ast.Paragraph, reader text.Reader, pc gmparser.Context) {
+	parent := node.Parent()
+	previous := node.PreviousSibling()
+	original := node.Lines()
+	lines := make([]text.Segment, original.Len())
+	for i := range lines {
+		lines[i] = original.At(i)
+	}
+
+	gmparser.LinkReferenceParagraphTransformer.Transform(node, reader, pc)
+
+	// goldmark replaces a paragraph that only held definitions with an empty text block
+	current := ast.Node(node)
+	remaining := node.Lines().Len()
+	if node.Parent() == nil {
+		current = parent.FirstChild()
+		if previous != nil {
+			current = previous.NextSibling()
+		}
+		remaining = 0
+	}
+
+	removed := len(lines) - remaining
+	if removed <= 0 {
+		return
+	}
+
+	block := &linkReferenceBlock{}
+	segments := text.NewSegments()
+	for _, line := range lines[:removed] {
+		segments.Append(line)
+	}
+	block.SetLines(segments)
+	parent.InsertBefore(parent, current, block)
+}
+
+// referenceLinkParser wraps goldmark's link parser and marks links and images
+// that were written in reference style, which goldmark otherwise resolves to
+// plain destinations
+type referenceLinkParser struct {
+	gmparser.InlineParser
+}
+
+// Parse parses a link and records the reference it was written with
+func (s *referenceLinkParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node == nil || len(line) == 0 || line[0] != ']' {
+		return node
+	}
+	if node.Kind() != ast.KindLink && node.Kind() != ast.KindImage {
+		return node
+	}
+
+	source := block.Source()
+	_, position := block.Position()
+	opening := openingBracket(source, segment.Start)
+	if opening >= 0 {
+		start := opening
+		if node.Kind() == ast.KindImage && start > 0 && source[start-1] == '!' {
+			start--
+		}
+		node.SetAttributeString(spanAttribute, sourceSpan{start, position.Start})
+	}
+
+	after := segment.Start + 1
+	switch {
+	case position.Start <= after:
+		node.SetAttributeString(referenceAttribute, "")
+	case source[after] == '[':
+		node.SetAttributeString(referenceAttribute, string(source[after:position.Start]))
+	default:
+		return node
+	}
+
+	// Collapsed and shortcut references use the bracketed text as label
+	if position.Start <= after+len("[]") && opening >= 0 {
+		label := source[opening+1 : segment.Start]
+		if _, ok := pc.Reference(util.ToLinkReference(label)); ok {
+			node.SetAttributeString(referenceLabelAttribute, string(label))
+		}
+	}
+	return node
+}
+
+// openingBracket returns the position of the bracket that the bracket at
+// position end closes, or -1
+func openingBracket(source []byte, end int) int {
+	depth := 0
+	for i := end - 1; i >= 0; i-- {
+		if escaped(source, i) {
+			continue
+		}
+		switch source[i] {
+		case ']':
+			depth++
+		case '[':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// escaped reports whether the byte at position i is escaped with a backslash
+func escaped(source []byte, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && source[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// CloseBlock forwards to the wrapped parser so its label state is cleared
+func (s *referenceLinkParser) CloseBlock(parent ast.Node, block text.Reader, pc gmparser.Context) {
+	if closer, ok := s.InlineParser.(gmparser.CloseBlocker); ok {
+		closer.CloseBlock(parent, block, pc)
+	}
+}
+
+// linkReference returns how a goldmark link or image refers to its
+// destination, and its reference label
+func linkReference(n ast.Node) (ReferenceStyle, string) {
+	value, ok := n.AttributeString(referenceAttribute)
+	if !ok {
+		return ReferenceInline, ""
+	}
+
+	label := ""
+	if value, ok := n.AttributeString(referenceLabelAttribute); ok {
+		label, _ = value.(string)
+	}
+
+	reference, _ := value.(string)
+	switch reference {
+	case "":
+		return ReferenceShortcut, label
+	case "[]":
+		return ReferenceCollapsed, label
+	default:
+		return ReferenceFull, reference[1 : len(reference)-1]
+	}
+}
+
+// convertLinkReferenceBlock converts the definitions held by a link reference block
+func (p *GoldmarkParser) convertLinkReferenceBlock(n ast.Node, source []byte) []Node {
+	var raw strings.Builder
+	lines := n.Lines()
+	lineStarts := make([]int, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		lineStarts[i] = raw.Len()
+		raw.Write(line.Value(source))
+	}
+
+	// Map offsets in the joined lines back to the source
+	sourceOffset := func(offset int) int {
+		i := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+		return lines.At(i).Start + offset - lineStarts[i]
+	}
+
+	definitions := make([]Node, 0)
+	for _, definition := range parseLinkReferenceDefinitions(raw.String()) {
+		if definition.End().Offset > 0 {
+			setSpan(definition, sourceSpan{
+				sourceOffset(definition.Pos().Offset),
+				sourceOffset(definition.End().Offset-1) + 1,
+			})
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// parseLinkReferenceDefinitions splits text that goldmark has already
+// recognized as link reference definitions into its definitions. The byte
+// range of each definition within raw is recorded on it.
+func parseLinkReferenceDefinitions(raw string) []*LinkReferenceDefinition {
+	var definitions []*LinkReferenceDefinition
+
+	rest := raw
+	for {
+		rest = strings.TrimLeft(rest, " \t\n")
+		if !strings.HasPrefix(rest, "[") {
+			break
+		}
+		start := len(raw) - len(rest)
+		labelEnd := indexUnescaped(rest[1:], ']') + 1
+		if labelEnd < 1 || labelEnd+1 >= len(rest) || rest[labelEnd+1] != ':' {
+			break
+		}
+
+		definition := &LinkReferenceDefinition{
+			Label: strings.Join(strings.Fields(rest[1:labelEnd]), " "),
+		}
+		rest = strings.TrimLeft(rest[labelEnd+2:], " \t\n")
+
+		if strings.HasPrefix(rest, "<") {
+			destinationEnd := indexUnescaped(rest[1:], '>') + 1
+			if destinationEnd < 1 {
+				break
+			}
+			definition.Destination = rest[1:destinationEnd]
+			rest = rest[destinationEnd+1:]
+		} else {
+			destinationEnd := strings.IndexAny(rest, " \t\n")
+			if destinationEnd < 0 {
+				destinationEnd = len(rest)
+			}
+			definition.Destination = rest[:destinationEnd]
+			rest = rest[destinationEnd:]
+		}
+
+		title := strings.TrimLeft(rest, " \t\n")
+		if title != "" && strings.ContainsRune(`"'(`, rune(title[0])) {
+			closer := title[0]
+			if closer == '(' {
+				closer = ')'
+			}
+			if titleEnd := indexUnescaped(title[1:], closer) + 1; titleEnd > 0 {
+				definition.Title = title[1:titleEnd]
+				rest = title[titleEnd+1:]
+			}
+		}
+		setSpan(definition, sourceSpan{start, len(raw) - len(rest)})
+
+		// The rest of the line is blank, otherwise goldmark would not have
+		// accepted the definition
+		if newline := strings.IndexByte(rest, '\n'); newline >= 0 {
+			rest = rest[newline+1:]
+		} else {
+			rest = ""
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	return definitions
+}
+
+// indexUnescaped returns the index of the first occurrence of c in s that is
+// not escaped with a backslash, or -1
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
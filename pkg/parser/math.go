@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// mathBlockParserPriority places the math block parser right after
+	// goldmark's fenced code block parser
+	mathBlockParserPriority = 750
+	// mathInlineParserPriority places the inline math parser right after
+	// goldmark's code span parser
+	mathInlineParserPriority = 150
+
+	// mathDelimiter opens and closes inline math
+	mathDelimiter = '$'
+	// displayMathDelimiter opens and closes display math
+	displayMathDelimiter = "$$"
+)
+
+var (
+	// kindMathBlock is the goldmark node kind of display math blocks
+	kindMathBlock = ast.NewNodeKind("MathBlock")
+	// kindInlineMath is the goldmark node kind of inline math
+	kindInlineMath = ast.NewNodeKind("InlineMath")
+)
+
+// mathExtension parses $$ ... $$ display math blocks and $x$ inline math
+// into nodes that keep their source as written. Formulas are not markdown,
+// so nothing inside them is interpreted.
+var mathExtension goldmark.Extender = &math{}
+
+type math struct{}
+
+// Extend registers the math block and inline parsers
+func (e *math) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(
+			util.Prioritized(mathBlockParser{}, mathBlockParserPriority),
+		),
+		gmparser.WithInlineParsers(
+			util.Prioritized(&spanParser{inlineMathParser{}}, mathInlineParserPriority),
+		),
+	)
+}
+
+// mathBlockNode is a display math block in the goldmark AST. Its lines are
+// the source lines from the opening to the closing delimiter.
+type mathBlockNode struct {
+	ast.BaseBlock
+	closed bool
+}
+
+// Kind returns the kind of math block nodes
+func (n *mathBlockNode) Kind() ast.NodeKind { return kindMathBlock }
+
+// IsRaw reports that the content of math blocks is not parsed
+func (n *mathBlockNode) IsRaw() bool { return true }
+
+// Dump dumps the node for debugging
+func (n *mathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// inlineMathNode is inline math in the goldmark AST. Its children are the
+// raw text segments between the delimiters, one per source line.
+type inlineMathNode struct {
+	ast.BaseInline
+	delimiter string
+}
+
+// Kind returns the kind of inline math nodes
+func (n *inlineMathNode) Kind() ast.NodeKind { return kindInlineMath }
+
+// Dump dumps the node for debugging
+func (n *inlineMathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathBlockParser parses display math blocks that start with a line opening
+// with $$ and end with a line closing with $$. A single line can hold the
+// whole block. An unclosed block runs to the end of its container, like a
+// fenced code block.
+type mathBlockParser struct{}
+
+// Trigger returns the character that opens math blocks
+func (b mathBlockParser) Trigger() []byte {
+	return []byte{mathDelimiter}
+}
+
+// Open starts a math block at a line beginning with $$
+func (b mathBlockParser) Open(_ ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte(displayMathDelimiter)) {
+		return nil, gmparser.NoChildren
+	}
+
+	node := &mathBlockNode{}
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+	rest := util.TrimRightSpace(line[pos+len(displayMathDelimiter):])
+	node.closed = bytes.HasSuffix(rest, []byte(displayMathDelimiter))
+	reader.AdvanceToEOL()
+	return node, gmparser.NoChildren
+}
+
+// Continue adds lines to the block up to and including the closing line
+func (b mathBlockParser) Continue(node ast.Node, reader text.Reader, _ gmparser.Context) gmparser.State {
+	block := node.(*mathBlockNode)
+	if block.closed {
+		return gmparser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return gmparser.Close
+	}
+	node.Lines().Append(text.NewSegment(segment.Start, segment.Stop))
+	reader.AdvanceToEOL()
+	if bytes.HasSuffix(util.TrimRightSpace(line), []byte(displayMathDelimiter)) {
+		block.closed = true
+		return gmparser.Close
+	}
+	return gmparser.Continue | gmparser.NoChildren
+}
+
+// Close finishes a math block
+func (b mathBlockParser) Close(ast.Node, text.Reader, gmparser.Context) {}
+
+// CanInterruptParagraph reports that math blocks can follow text directly
+func (b mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine reports that math blocks cannot open with indented code indentation
+func (b mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// inlineMathParser parses $x$ and $$x$$ inline math. A single $ must be
+// followed by a non-space and its closing $ preceded by a non-space and not
+// followed by a digit, so that amounts such as $5 and $10 stay text.
+type inlineMathParser struct{}
+
+// Trigger returns the character that opens inline math
+func (s inlineMathParser) Trigger() []byte {
+	return []byte{mathDelimiter}
+}
+
+// Parse parses inline math, which may span several lines of a paragraph
+func (s inlineMathParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	opener := 1
+	if len(line) > 1 && line[1] == mathDelimiter {
+		opener = len(displayMathDelimiter)
+	}
+	if opener == 1 && (len(line) < 2 || util.IsSpace(line[1])) {
+		return nil
+	}
+
+	l, start := block.Position()
+	block.Advance(opener)
+	node := &inlineMathNode{delimiter: strings.Repeat(string(mathDelimiter), opener)}
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			block.SetPosition(l, start)
+			return nil
+		}
+		if closer := inlineMathCloser(line, opener); closer >= 0 {
+			if closer > 0 {
+				node.AppendChild(node, ast.NewRawTextSegment(segment.WithStop(segment.Start+closer)))
+			}
+			block.Advance(closer + opener)
+			return node
+		}
+		node.AppendChild(node, ast.NewRawTextSegment(segment))
+		block.AdvanceLine()
+	}
+}
+
+// inlineMathCloser returns the index of the closing delimiter in line, or -1
+func inlineMathCloser(line []byte, opener int) int {
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] != mathDelimiter:
+		default:
+			run := 1
+			for i+run < len(line) && line[i+run] == mathDelimiter {
+				run++
+			}
+			if run == opener && (opener > 1 || validInlineMathCloser(line, i)) {
+				return i
+			}
+			i += run - 1
+		}
+	}
+	return -1
+}
+
+// validInlineMathCloser reports whether the $ at i can close inline math. A
+// $ at the start of a line follows a line break, which counts as a space.
+func validInlineMathCloser(line []byte, i int) bool {
+	if i == 0 || util.IsSpace(line[i-1]) {
+		return false
+	}
+	return i+1 >= len(line) || line[i+1] < '0' || line[i+1] > '9'
+}
+
+// convertMathBlock converts a math block, keeping its source lines
+func (p *GoldmarkParser) convertMathBlock(n ast.Node, source []byte) *MathBlock {
+	var sb strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		sb.Write(segment.Value(source))
+	}
+	return &MathBlock{Content: strings.TrimRight(sb.String(), "\r\n")}
+}
+
+// inlineMathContent returns the source of inline math with its delimiters
+func inlineMathContent(n *inlineMathNode, source []byte) string {
+	var sb strings.Builder
+	sb.WriteString(n.delimiter)
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if text, ok := child.(*ast.Text); ok {
+			sb.Write(text.Segment.Value(source))
+		}
+	}
+	sb.WriteString(n.delimiter)
+	return sb.String()
+}
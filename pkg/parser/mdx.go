@@ -0,0 +1,552 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// jsxBlockParserPriority places the JSX block parser right before
+	// goldmark's HTML block parser, which then only sees HTML comments and
+	// other markup that is not JSX
+	jsxBlockParserPriority = 850
+	// esmParserPriority places the ESM parser right before goldmark's paragraph parser
+	esmParserPriority = 950
+	// jsxInlineParserPriority places the inline JSX parser right before
+	// goldmark's raw HTML parser
+	jsxInlineParserPriority = 350
+)
+
+var (
+	// kindESM is the goldmark node kind of MDX import and export statements
+	kindESM = ast.NewNodeKind("ESM")
+	// kindJSX is the goldmark node kind of MDX JSX blocks
+	kindJSX = ast.NewNodeKind("JSX")
+
+	// esmStart matches the first line of an MDX import or export statement
+	esmStart = regexp.MustCompile(`^(import|export)([ \t{*"']|$)`)
+)
+
+// mdxExtension parses the MDX additions to Markdown: import and export
+// statements, and JSX tags and {expressions}, both on lines of their own
+// and inside paragraphs. All of them are kept as written, while the
+// Markdown between JSX tags is parsed as usual.
+var mdxExtension goldmark.Extender = &mdx{}
+
+type mdx struct{}
+
+// Extend registers the ESM and JSX block parsers and the inline JSX parser
+func (e *mdx) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		gmparser.WithBlockParsers(
+			util.Prioritized(jsxBlockParser{}, jsxBlockParserPriority),
+			util.Prioritized(esmParser{}, esmParserPriority),
+		),
+		gmparser.WithInlineParsers(
+			util.Prioritized(&spanParser{jsxInlineParser{}}, jsxInlineParserPriority),
+		),
+	)
+}
+
+// esmNode is a block of import and export statements in the goldmark AST
+type esmNode struct {
+	ast.BaseBlock
+}
+
+// Kind returns the kind of ESM nodes
+func (n *esmNode) Kind() ast.NodeKind { return kindESM }
+
+// IsRaw reports that statements are not parsed as Markdown
+func (n *esmNode) IsRaw() bool { return true }
+
+// Dump dumps the node for debugging
+func (n *esmNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// jsxNode is a JSX block in the goldmark AST. Its lines are the source of
+// its tags; an element whose opening tag stands alone is a container for
+// the blocks up to its closing tag.
+type jsxNode struct {
+	ast.BaseBlock
+	// name is the name of the element the node is a container for, empty
+	// for fragments
+	name      string
+	container bool
+	// pending is set while the tags continue on the next line
+	pending bool
+	// indent is the indentation of the first child line, -1 before it is seen
+	indent  int
+	closing text.Segment
+	closed  bool
+}
+
+// Kind returns the kind of JSX nodes
+func (n *jsxNode) Kind() ast.NodeKind { return kindJSX }
+
+// IsRaw reports that the tags are not parsed as inline content
+func (n *jsxNode) IsRaw() bool { return true }
+
+// Dump dumps the node for debugging
+func (n *jsxNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.name}, nil)
+}
+
+// setTags makes the node a container if its lines hold a single opening
+// tag; any other tags and expressions complete the node by themselves
+func (n *jsxNode) setTags(tags []jsxTag) {
+	n.pending = false
+	if len(tags) == 1 && tags[0].opens() {
+		n.container = true
+		n.name = tags[0].name
+	}
+}
+
+// closedBy reports whether line is the closing tag of the element. A closing
+// tag inside raw content such as a code block, or one that belongs to a
+// nested element of the same name, does not count.
+func (n *jsxNode) closedBy(line []byte, pc gmparser.Context) bool {
+	tags, state := scanJSX(util.TrimLeftSpace(line))
+	if state != jsxComplete || len(tags) != 1 || !tags[0].closing || tags[0].name != n.name {
+		return false
+	}
+
+	inner := false
+	for _, block := range pc.OpenedBlocks() {
+		if block.Node == n {
+			inner = true
+			continue
+		}
+		if !inner {
+			continue
+		}
+		switch node := block.Node.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *mathBlockNode:
+			return false
+		case *jsxNode:
+			if node.container && node.name == n.name {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// esmParser parses MDX import and export statements at the top level of the
+// document. A block of statements runs up to the next blank line.
+type esmParser struct{}
+
+// Trigger returns the characters that start import and export statements
+func (b esmParser) Trigger() []byte {
+	return []byte{'i', 'e'}
+}
+
+// Open starts a block of statements at an unindented import or export line
+func (b esmParser) Open(parent ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	if parent.Kind() != ast.KindDocument || pc.BlockOffset() != 0 || !esmStart.Match(line) {
+		return nil, gmparser.NoChildren
+	}
+
+	node := &esmNode{}
+	node.Lines().Append(segment)
+	reader.AdvanceToEOL()
+	return node, gmparser.NoChildren
+}
+
+// Continue adds lines to the block up to the next blank line
+func (b esmParser) Continue(node ast.Node, reader text.Reader, _ gmparser.Context) gmparser.State {
+	line, segment := reader.PeekLine()
+	if util.IsBlank(line) {
+		return gmparser.Close
+	}
+	node.Lines().Append(segment)
+	reader.AdvanceToEOL()
+	return gmparser.Continue | gmparser.NoChildren
+}
+
+// Close finishes a block of statements
+func (b esmParser) Close(ast.Node, text.Reader, gmparser.Context) {}
+
+// CanInterruptParagraph reports that statements must start a new block, as in MDX
+func (b esmParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine reports that statements cannot be indented
+func (b esmParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// jsxBlockParser parses lines made only of JSX tags and expressions. Tags
+// may span lines. A lone opening tag starts an element whose children are
+// parsed as Markdown, with their common indentation removed, up to the line
+// holding its closing tag.
+type jsxBlockParser struct{}
+
+// Trigger returns the characters that open JSX tags and expressions
+func (b jsxBlockParser) Trigger() []byte {
+	return []byte{'<', '{'}
+}
+
+// Open starts a JSX block at a line that begins with a tag or expression
+func (b jsxBlockParser) Open(_ ast.Node, reader text.Reader, pc gmparser.Context) (ast.Node, gmparser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, gmparser.NoChildren
+	}
+	tags, state := scanJSX(line[pos:])
+	if state == jsxInvalid {
+		return nil, gmparser.NoChildren
+	}
+
+	node := &jsxNode{pending: true, indent: -1}
+	node.Lines().Append(text.NewSegment(segment.Start+pos, segment.Stop))
+	reader.AdvanceToEOL()
+	if state == jsxComplete {
+		node.setTags(tags)
+	}
+	if node.container {
+		return node, gmparser.HasChildren
+	}
+	return node, gmparser.NoChildren
+}
+
+// Continue adds the lines of tags that span lines, and then passes the lines
+// of an element on to its children until the closing tag
+func (b jsxBlockParser) Continue(node ast.Node, reader text.Reader, pc gmparser.Context) gmparser.State {
+	jsx := node.(*jsxNode)
+	line, segment := reader.PeekLine()
+	if jsx.pending {
+		start := node.Lines().At(0).Start
+		if util.IsBlank(line) && !continuesPast(reader.Source()[start:], segment.Start-start) {
+			return gmparser.Close
+		}
+		node.Lines().Append(segment)
+		reader.AdvanceToEOL()
+		switch tags, state := scanJSX(jsxSource(node, reader.Source())); state {
+		case jsxComplete:
+			jsx.setTags(tags)
+		case jsxInvalid:
+			// Kept as written, like tags that never end
+			jsx.pending = false
+		}
+		return gmparser.Continue | gmparser.NoChildren
+	}
+	if !jsx.container {
+		return gmparser.Close
+	}
+
+	if util.IsBlank(line) {
+		reader.AdvanceToEOL()
+		return gmparser.Continue | gmparser.HasChildren
+	}
+	if jsx.closedBy(line, pc) {
+		pos := util.FirstNonSpacePosition(line)
+		jsx.closing = text.NewSegment(segment.Start+pos, segment.Stop)
+		jsx.closed = true
+		reader.AdvanceToEOL()
+		return gmparser.Close
+	}
+
+	indent, _ := util.IndentWidth(line, reader.LineOffset())
+	if jsx.indent < 0 {
+		jsx.indent = indent
+	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), min(indent, jsx.indent))
+	reader.AdvanceAndSetPadding(pos, padding)
+	return gmparser.Continue | gmparser.HasChildren
+}
+
+// Close finishes a JSX block
+func (b jsxBlockParser) Close(ast.Node, text.Reader, gmparser.Context) {}
+
+// CanInterruptParagraph reports that a tag after a line of text is part of
+// the paragraph, as in MDX
+func (b jsxBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine reports that JSX blocks cannot open with indented code indentation
+func (b jsxBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// jsxInlineParser parses a JSX tag or expression inside a paragraph into a
+// raw HTML node, so that it is kept as written
+type jsxInlineParser struct{}
+
+// Trigger returns the characters that open JSX tags and expressions
+func (s jsxInlineParser) Trigger() []byte {
+	return []byte{'<', '{'}
+}
+
+// Parse parses a tag or expression that ends on the same line
+func (s jsxInlineParser) Parse(_ ast.Node, block text.Reader, _ gmparser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	_, end, state := scanJSXItem(line, 0)
+	if state != jsxComplete {
+		return nil
+	}
+
+	node := ast.NewRawHTML()
+	node.Segments.Append(segment.WithStop(segment.Start + end))
+	block.Advance(end)
+	return node
+}
+
+// continuesPast reports whether the tags and expressions at the start of src
+// are complete and one of them ends after offset. Blank lines may appear
+// inside a tag, but a tag that never ends is not allowed to run on past one.
+func continuesPast(src []byte, offset int) bool {
+	for i := skipJSXSpace(src, 0); i < offset; i = skipJSXSpace(src, i) {
+		_, end, state := scanJSXItem(src, i)
+		if state != jsxComplete {
+			return false
+		}
+		i = end
+		if i > offset {
+			return true
+		}
+	}
+	return false
+}
+
+// jsxSource returns the source lines of a JSX block
+func jsxSource(n ast.Node, source []byte) []byte {
+	var buf bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(source))
+	}
+	return buf.Bytes()
+}
+
+// convertESM converts a block of import and export statements, keeping its source lines
+func (p *GoldmarkParser) convertESM(n ast.Node, source []byte) *ESM {
+	return &ESM{Content: strings.TrimRight(string(jsxSource(n, source)), "\r\n")}
+}
+
+// convertJSX converts a JSX block with the Markdown children of its element
+func (p *GoldmarkParser) convertJSX(n ast.Node, source []byte) *JSX {
+	node := n.(*jsxNode)
+	jsx := &JSX{
+		Opening:  strings.TrimRight(string(jsxSource(n, source)), "\r\n"),
+		Indent:   max(node.indent, 0),
+		Children: p.convertChildren(n, source),
+	}
+	if node.closed {
+		jsx.Closing = strings.TrimRight(string(node.closing.Value(source)), " \t\r\n")
+	}
+	jsx.Tight = n.FirstChild() == nil || !n.FirstChild().HasBlankPreviousLines()
+	return jsx
+}
+
+// jsxScanState is the result of scanning for JSX tags and expressions
+type jsxScanState int
+
+const (
+	// jsxComplete means that the scanned tags and expressions are complete
+	jsxComplete jsxScanState = iota
+	// jsxIncomplete means that the source ended inside a tag or expression
+	jsxIncomplete
+	// jsxInvalid means that the source holds something else
+	jsxInvalid
+)
+
+// jsxTag is a JSX tag or expression found by scanJSX
+type jsxTag struct {
+	name        string // element name, empty for fragments and expressions
+	expression  bool   // a {...} expression rather than a tag
+	closing     bool   // a closing tag: </name>
+	selfClosing bool   // a tag without children: <name />
+}
+
+// opens reports whether the tag opens an element with children
+func (t jsxTag) opens() bool {
+	return !t.expression && !t.closing && !t.selfClosing
+}
+
+// scanJSX scans src as a sequence of JSX tags and expressions separated by
+// whitespace, which may span lines
+func scanJSX(src []byte) ([]jsxTag, jsxScanState) {
+	var tags []jsxTag
+	i := skipJSXSpace(src, 0)
+	for i < len(src) {
+		tag, end, state := scanJSXItem(src, i)
+		if state != jsxComplete {
+			return nil, state
+		}
+		tags = append(tags, tag)
+		i = skipJSXSpace(src, end)
+	}
+	if len(tags) == 0 {
+		return nil, jsxInvalid
+	}
+	return tags, jsxComplete
+}
+
+// scanJSXItem scans the tag or expression at src[i] and returns the index
+// right after it
+func scanJSXItem(src []byte, i int) (jsxTag, int, jsxScanState) {
+	switch src[i] {
+	case '{':
+		end, state := scanJSXExpression(src, i)
+		return jsxTag{expression: true}, end, state
+	case '<':
+		return scanJSXTag(src, i)
+	}
+	return jsxTag{}, i, jsxInvalid
+}
+
+// scanJSXTag scans the tag at src[i]: a fragment (<> or </>) or an element
+// name followed by attributes, which are names with an optional string or
+// expression value, and {...props} spreads
+func scanJSXTag(src []byte, i int) (jsxTag, int, jsxScanState) {
+	var tag jsxTag
+	i = skipJSXSpace(src, i+1)
+	if i < len(src) && src[i] == '/' {
+		tag.closing = true
+		i = skipJSXSpace(src, i+1)
+	}
+	if i >= len(src) {
+		return tag, i, jsxIncomplete
+	}
+	if src[i] == '>' {
+		return tag, i + 1, jsxComplete
+	}
+	if !isJSXNameStart(src[i]) {
+		return tag, i, jsxInvalid
+	}
+	end := scanJSXName(src, i)
+	tag.name = string(src[i:end])
+
+	for i = end; ; {
+		i = skipJSXSpace(src, i)
+		if i >= len(src) {
+			return tag, i, jsxIncomplete
+		}
+		switch c := src[i]; {
+		case c == '>':
+			return tag, i + 1, jsxComplete
+		case c == '/':
+			if i+1 >= len(src) {
+				return tag, i, jsxIncomplete
+			}
+			if src[i+1] != '>' {
+				return tag, i, jsxInvalid
+			}
+			tag.selfClosing = true
+			return tag, i + len("/>"), jsxComplete
+		case c == '{':
+			end, state := scanJSXExpression(src, i)
+			if state != jsxComplete {
+				return tag, end, state
+			}
+			i = end
+		case isJSXNameStart(c):
+			i = skipJSXSpace(src, scanJSXName(src, i))
+			if i >= len(src) {
+				return tag, i, jsxIncomplete
+			}
+			if src[i] != '=' {
+				continue
+			}
+			end, state := scanJSXAttributeValue(src, skipJSXSpace(src, i+1))
+			if state != jsxComplete {
+				return tag, end, state
+			}
+			i = end
+		default:
+			return tag, i, jsxInvalid
+		}
+	}
+}
+
+// scanJSXAttributeValue scans the quoted string or expression at src[i]
+func scanJSXAttributeValue(src []byte, i int) (int, jsxScanState) {
+	if i >= len(src) {
+		return i, jsxIncomplete
+	}
+	switch src[i] {
+	case '"', '\'':
+		end := bytes.IndexByte(src[i+1:], src[i])
+		if end < 0 {
+			return len(src), jsxIncomplete
+		}
+		return i + end + len(`""`), jsxComplete
+	case '{':
+		return scanJSXExpression(src, i)
+	}
+	return i, jsxInvalid
+}
+
+// scanJSXExpression scans the braces at src[i] up to the matching closing
+// brace, skipping over JavaScript strings and comments
+func scanJSXExpression(src []byte, i int) (int, jsxScanState) {
+	depth := 0
+	for ; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1, jsxComplete
+			}
+		case c == '"' || c == '\'' || c == '`':
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+		case bytes.HasPrefix(src[i:], []byte("/*")):
+			end := bytes.Index(src[i+len("/*"):], []byte("*/"))
+			if end < 0 {
+				return len(src), jsxIncomplete
+			}
+			i += len("/*") + end + len("*/") - 1
+		case bytes.HasPrefix(src[i:], []byte("//")):
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return len(src), jsxIncomplete
+			}
+			i += end
+		}
+	}
+	return len(src), jsxIncomplete
+}
+
+// scanJSXName returns the index right after the element or attribute name
+// at src[i], which may include namespace colons and member dots
+func scanJSXName(src []byte, i int) int {
+	for i < len(src) && (isJSXNameStart(src[i]) || util.IsNumeric(src[i]) ||
+		src[i] == '-' || src[i] == '.' || src[i] == ':') {
+		i++
+	}
+	return i
+}
+
+// isJSXNameStart reports whether c can start an element or attribute name
+func isJSXNameStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= utf8.RuneSelf
+}
+
+// skipJSXSpace returns the index of the first byte at or after i that is not
+// whitespace, including line endings
+func skipJSXSpace(src []byte, i int) int {
+	for i < len(src) && util.IsSpace(src[i]) {
+		i++
+	}
+	return i
+}
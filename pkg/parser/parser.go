@@ -30,7 +30,7 @@ func (p *BasicParser) Parse(content []byte) (*Document, error) {
 	doc := &Document{
 		Children: []Node{
 			&Paragraph{
-				Text: string(content),
+				Inlines: []Node{&Text{Content: string(content)}},
 			},
 		},
 	}
@@ -44,29 +44,29 @@ func (p *BasicParser) Validate() error {
 
 // Helper functions for node manipulation
 
-// FindNodes finds all nodes of a specific type in the tree
+// FindNodes finds all nodes of a specific type in the tree, including nodes
+// nested in containers and inline content
 func FindNodes(doc *Document, nodeType NodeType) []Node {
 	var found []Node
-	walker := NewWalker(doc)
-
-	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
+	Inspect(doc, func(node Node) bool {
 		if node.Type() == nodeType {
 			found = append(found, node)
 		}
-	}
+		return true
+	})
 
 	return found
 }
 
-// FindFirstNode finds the first node of a specific type
+// FindFirstNode finds the first node of a specific type in document order
 func FindFirstNode(doc *Document, nodeType NodeType) Node {
-	walker := NewWalker(doc)
-
-	for node, ok := walker.Next(); ok; node, ok = walker.Next() {
-		if node.Type() == nodeType {
-			return node
+	var first Node
+	Inspect(doc, func(node Node) bool {
+		if first == nil && node.Type() == nodeType {
+			first = node
 		}
-	}
+		return first == nil
+	})
 
-	return nil
+	return first
 }
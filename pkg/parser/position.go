@@ -0,0 +1,359 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// spanAttribute is the goldmark node attribute that records the source
+	// byte range of inline nodes whose own segments do not cover their markup
+	spanAttribute = "mdfmt-span"
+)
+
+// Position is a location in the source document
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // byte column, starting at 1
+}
+
+// IsValid reports whether the position was recorded from the source. Nodes
+// created by formatters have no position.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as line:column
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Range is the part of the source a node was parsed from. It is embedded in
+// every node and provides the position methods of the Node interface.
+type Range struct {
+	start Position
+	end   Position
+}
+
+// Pos returns the position of the first byte of the node
+func (r *Range) Pos() Position { return r.start }
+
+// End returns the position immediately after the last byte of the node
+func (r *Range) End() Position { return r.end }
+
+// SetRange sets the source range of the node
+func (r *Range) SetRange(start, end Position) {
+	r.start, r.end = start, end
+}
+
+// ranged is implemented by every node through its embedded Range
+type ranged interface {
+	SetRange(start, end Position)
+}
+
+// sourceSpan is a byte range [start, end) of the source
+type sourceSpan struct {
+	start int
+	end   int
+}
+
+// setSpan records the byte range of a node. Lines and columns are filled in
+// by resolvePositions once the whole document has been converted.
+func setSpan(node Node, span sourceSpan) {
+	if r, ok := node.(ranged); ok && span.end > span.start {
+		r.SetRange(Position{Offset: span.start}, Position{Offset: span.end})
+	}
+}
+
+// resolvePositions fills in the line and column of every node with a recorded byte range
+func resolvePositions(doc *Document, source []byte) {
+	lineStarts := []int{0}
+	for i, c := range source {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	resolve := func(offset int) Position {
+		line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+		return Position{Offset: offset, Line: line, Column: offset - lineStarts[line-1] + 1}
+	}
+
+	doc.SetRange(resolve(0), resolve(len(source)))
+	Inspect(doc, func(node Node) bool {
+		if r, ok := node.(ranged); ok && node.End().Offset > 0 && !node.Pos().IsValid() {
+			r.SetRange(resolve(node.Pos().Offset), resolve(node.End().Offset))
+		}
+		return true
+	})
+}
+
+// nodeSpan returns the byte range a goldmark node was parsed from. It is
+// derived from the node's segments, widened to cover the markup around them
+// where goldmark leaves it out.
+func nodeSpan(n ast.Node, source []byte) (sourceSpan, bool) {
+	if value, ok := n.AttributeString(spanAttribute); ok {
+		span, ok := value.(sourceSpan)
+		return span, ok
+	}
+
+	switch node := n.(type) {
+	case *ast.Text:
+		return sourceSpan{node.Segment.Start, node.Segment.Stop}, true
+	case *ast.AutoLink:
+		// Bare URLs found by GFM linkify directly follow the text before them
+		label := len(node.Label(source))
+		if previous, ok := node.PreviousSibling().(*ast.Text); ok {
+			return sourceSpan{previous.Segment.Stop, previous.Segment.Stop + label}, true
+		}
+		if next, ok := node.NextSibling().(*ast.Text); ok {
+			return sourceSpan{next.Segment.Start - label, next.Segment.Start}, true
+		}
+		return sourceSpan{}, false
+	case *ast.RawHTML:
+		return segmentsSpan(node.Segments, source)
+	}
+
+	var span sourceSpan
+	var ok bool
+	if n.Type() == ast.TypeBlock {
+		span, ok = segmentsSpan(n.Lines(), source)
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if childSpan, found := nodeSpan(child, source); found {
+			span, ok = unionSpan(span, ok, childSpan), true
+		}
+	}
+	if !ok {
+		return span, false
+	}
+
+	switch node := n.(type) {
+	case *ast.Emphasis:
+		span = widenSpan(source, span, "*_", node.Level)
+	case *extast.Strikethrough:
+		span = widenSpan(source, span, "~", StrongEmphasisLevel)
+	case *ast.Heading:
+		if start := headingStart(source, span.start); start != span.start {
+			span.start = start
+			span.end = lineEnd(source, span.end)
+		} else {
+			// The underline of a setext heading is the line after its text
+			span.end = lineEnd(source, nextLineStart(source, span.end))
+		}
+	case *ast.FencedCodeBlock:
+		span = fencedCodeSpan(node, source, span)
+	case *ast.ListItem:
+		span.start = listMarkerStart(source, span.start)
+	case *ast.Blockquote:
+		if start := skipSpacesBack(source, span.start); start > 0 && source[start-1] == '>' {
+			span.start = start - 1
+		}
+	case *extast.DefinitionDescription:
+		if start := skipSpacesBack(source, span.start); start > 0 && source[start-1] == ':' {
+			span.start = start - 1
+		}
+	case *extast.Table, *extast.TableHeader, *extast.TableRow:
+		span.start = tableRowStart(source, span.start)
+		span.end = lineEnd(source, span.end)
+	case *extast.Footnote:
+		// The label precedes the first block on the same line
+		start := lineStart(source, span.start)
+		if label := bytes.LastIndex(source[start:span.start], []byte("[^")); label >= 0 {
+			span.start = start + label
+		}
+	case *jsxNode:
+		if node.closed {
+			span.end = max(span.end, lineEnd(source, node.closing.Start))
+		}
+	case *ast.HTMLBlock:
+		if node.HasClosure() {
+			span.end = max(span.end, lineEnd(source, node.ClosureLine.Start))
+		}
+	}
+	return span, true
+}
+
+// segmentsSpan returns the byte range covered by segments, without the
+// line ending of the last one
+func segmentsSpan(segments *text.Segments, source []byte) (sourceSpan, bool) {
+	if segments == nil || segments.Len() == 0 {
+		return sourceSpan{}, false
+	}
+	span := sourceSpan{segments.At(0).Start, segments.At(segments.Len() - 1).Stop}
+	for span.end > span.start && (source[span.end-1] == '\n' || source[span.end-1] == '\r') {
+		span.end--
+	}
+	return span, true
+}
+
+// unionSpan returns the smallest range covering span, if ok, and other
+func unionSpan(span sourceSpan, ok bool, other sourceSpan) sourceSpan {
+	if !ok {
+		return other
+	}
+	return sourceSpan{min(span.start, other.start), max(span.end, other.end)}
+}
+
+// widenSpan extends a span over up to limit delimiter characters on each side
+func widenSpan(source []byte, span sourceSpan, delimiters string, limit int) sourceSpan {
+	for i := 0; i < limit && span.start > 0 && containsByte(delimiters, source[span.start-1]); i++ {
+		span.start--
+	}
+	for i := 0; i < limit && span.end < len(source) && containsByte(delimiters, source[span.end]); i++ {
+		span.end++
+	}
+	return span
+}
+
+// skipSpacesBack moves start back over the spaces and tabs before it
+func skipSpacesBack(source []byte, start int) int {
+	for start > 0 && (source[start-1] == ' ' || source[start-1] == '\t') {
+		start--
+	}
+	return start
+}
+
+// headingStart moves the start of heading text back to its ATX marker
+func headingStart(source []byte, start int) int {
+	marker := skipSpacesBack(source, start)
+	hashes := marker
+	for hashes > 0 && source[hashes-1] == '#' {
+		hashes--
+	}
+	if hashes < marker {
+		return hashes
+	}
+	return start
+}
+
+// listMarkerStart moves the start of list item content back to its marker
+func listMarkerStart(source []byte, start int) int {
+	marker := skipSpacesBack(source, start)
+	if marker == 0 {
+		return start
+	}
+	switch source[marker-1] {
+	case '-', '+', '*':
+		return marker - 1
+	case '.', ')':
+		digits := marker - 1
+		for digits > 0 && source[digits-1] >= '0' && source[digits-1] <= '9' {
+			digits--
+		}
+		if digits < marker-1 {
+			return digits
+		}
+	}
+	return start
+}
+
+// tableRowStart moves the start of the first cell of a table row back over
+// the leading pipe
+func tableRowStart(source []byte, start int) int {
+	pipe := skipSpacesBack(source, start)
+	if pipe > 0 && source[pipe-1] == '|' {
+		return skipSpacesBack(source, pipe-1)
+	}
+	return start
+}
+
+// lineStart returns the offset of the start of the line holding offset
+func lineStart(source []byte, offset int) int {
+	for offset > 0 && source[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// nextLineStart returns the offset of the line after the one holding offset
+func nextLineStart(source []byte, offset int) int {
+	offset = lineEnd(source, offset)
+	if offset < len(source) && source[offset] == '\r' {
+		offset++
+	}
+	if offset < len(source) && source[offset] == '\n' {
+		offset++
+	}
+	return offset
+}
+
+// lineEnd returns the offset of the line ending at or after offset
+func lineEnd(source []byte, offset int) int {
+	for offset < len(source) && source[offset] != '\n' && source[offset] != '\r' {
+		offset++
+	}
+	return offset
+}
+
+// fencedCodeSpan widens the content span of a fenced code block to its fence
+// lines. The opening fence is the line with the info string, or the line
+// before the content.
+func fencedCodeSpan(code *ast.FencedCodeBlock, source []byte, span sourceSpan) sourceSpan {
+	opening := span.start - 1
+	if code.Info != nil {
+		opening = min(span.start, code.Info.Segment.Start)
+	}
+	for opening > 0 && source[opening-1] != '\n' && source[opening-1] != '`' && source[opening-1] != '~' {
+		opening--
+	}
+	for opening > 0 && (source[opening-1] == '`' || source[opening-1] == '~') {
+		opening--
+	}
+	if opening < 0 || (source[opening] != '`' && source[opening] != '~') {
+		return span
+	}
+	span.start = opening
+
+	// The closing fence, if any, is the line after the content
+	if span.end < len(source) {
+		closing := span.end
+		for closing < len(source) && strings.IndexByte("\r\n \t>", source[closing]) >= 0 {
+			closing++
+		}
+		if closing < len(source) && source[closing] == source[opening] {
+			span.end = lineEnd(source, closing)
+		}
+	}
+	return span
+}
+
+// containsByte reports whether s contains the byte c
+func containsByte(s string, c byte) bool {
+	return strings.IndexByte(s, c) >= 0
+}
+
+// spanParser wraps an inline parser and records the range of source it
+// consumed on the nodes it returns
+type spanParser struct {
+	gmparser.InlineParser
+}
+
+// Parse parses an inline node and records its source range
+func (s *spanParser) Parse(parent ast.Node, block text.Reader, pc gmparser.Context) ast.Node {
+	_, segment := block.PeekLine()
+	node := s.InlineParser.Parse(parent, block, pc)
+	if node != nil {
+		_, position := block.Position()
+		node.SetAttributeString(spanAttribute, sourceSpan{segment.Start, position.Start})
+	}
+	return node
+}
+
+// CloseBlock forwards to the wrapped parser if it keeps state per block
+func (s *spanParser) CloseBlock(parent ast.Node, block text.Reader, pc gmparser.Context) {
+	if closer, ok := s.InlineParser.(gmparser.CloseBlocker); ok {
+		closer.CloseBlock(parent, block, pc)
+	}
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// escapeOrEntity matches a backslash escape or an entity reference in text
+var escapeOrEntity = regexp.MustCompile("\\\\[!-/:-@[-`{-~]|&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});")
+
+// slugger generates heading anchors the way GitHub does: the heading text is
+// lowercased, punctuation and symbols are dropped and each space becomes a
+// hyphen. Letters outside ASCII are kept, so "Café & Things!" becomes
+// "café--things". A slug that is already taken gets a -1, -2... suffix.
+type slugger struct {
+	occurrences map[string]int
+}
+
+// newSlugger creates a slugger for which the given IDs are already taken
+func newSlugger(taken []string) *slugger {
+	s := &slugger{occurrences: make(map[string]int)}
+	for _, id := range taken {
+		s.occurrences[id] = 0
+	}
+	return s
+}
+
+// slug returns a slug for text that is unique among the slugs returned so
+// far, or "" if text has no characters to keep
+func (s *slugger) slug(text string) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.Pc):
+			return r
+		}
+		return -1
+	}, strings.ToLower(text))
+	if base == "" {
+		return ""
+	}
+
+	result := base
+	for {
+		if _, ok := s.occurrences[result]; !ok {
+			break
+		}
+		s.occurrences[base]++
+		result = base + "-" + strconv.Itoa(s.occurrences[base])
+	}
+	s.occurrences[result] = 0
+	return result
+}
+
+// assignHeadingIDs sets the AutoID of every heading in doc without an ID of
+// its own, in document order. IDs written in attribute blocks are never
+// generated again.
+func assignHeadingIDs(doc *Document) {
+	var headings []*Heading
+	var taken []string
+	Inspect(doc, func(node Node) bool {
+		if heading, ok := node.(*Heading); ok {
+			headings = append(headings, heading)
+			if heading.ID != "" {
+				taken = append(taken, heading.ID)
+			}
+		}
+		return true
+	})
+
+	slugs := newSlugger(taken)
+	for _, heading := range headings {
+		if heading.ID != "" {
+			continue
+		}
+		heading.AutoID = slugs.slug(anchorText(heading.Inlines))
+	}
+}
+
+// anchorText returns the text GitHub makes a heading's anchor from: the text
+// as it reads, with escapes and entities decoded. Images and inline HTML are
+// left out, as the alt text and tags are not part of it.
+func anchorText(nodes []Node) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			sb.WriteString(escapeOrEntity.ReplaceAllStringFunc(n.Content, decodeEscapeOrEntity))
+		case *CodeSpan:
+			sb.WriteString(n.Content)
+		case *Autolink:
+			sb.WriteString(n.URL)
+		case *InlineMath:
+			sb.WriteString(n.Content)
+		case *SoftBreak, *HardBreak:
+			sb.WriteString("\n")
+		case *Emphasis:
+			sb.WriteString(anchorText(n.Children))
+		case *Strong:
+			sb.WriteString(anchorText(n.Children))
+		case *Strikethrough:
+			sb.WriteString(anchorText(n.Children))
+		case *Link:
+			sb.WriteString(anchorText(n.Children))
+		}
+	}
+	return sb.String()
+}
+
+// decodeEscapeOrEntity returns the character a backslash escape or entity
+// reference stands for. Unknown entities are kept as written.
+func decodeEscapeOrEntity(match string) string {
+	if match[0] == '\\' {
+		return match[1:]
+	}
+	return html.UnescapeString(match)
+}
@@ -0,0 +1,293 @@
+package parser
+
+import "fmt"
+
+// Children returns the child nodes of a node in document order: the front
+// matter and blocks of a document, the blocks of containers, list items,
+// table rows and cells, and inline content. Leaf nodes have no children.
+func Children(node Node) []Node {
+	var nodes []Node
+	for _, field := range childFields(node) {
+		nodes = append(nodes, field.nodes...)
+	}
+	return nodes
+}
+
+// Inspect traverses the tree rooted at node in depth-first order, like
+// go/ast.Inspect. It calls fn for every node before its children; when fn
+// returns false, the children of that node are skipped.
+func Inspect(node Node, fn func(Node) bool) {
+	Visit(node, func(c *Cursor) bool { return fn(c.Node()) }, nil)
+}
+
+// Visit traverses the tree rooted at node in depth-first order and returns
+// the root, which differs from node if enter or exit replaced it.
+//
+// enter is called for a node before its children and exit after them; either
+// may be nil. When enter returns false the children of the node are skipped,
+// but exit is still called. When exit returns false the traversal stops.
+// A node deleted by enter has neither its children visited nor exit called.
+//
+// The Cursor passed to the callbacks can replace, delete or insert around
+// the current node. Children are read after enter returns, so enter may
+// also rewrite the children of the current node directly.
+func Visit(node Node, enter, exit func(*Cursor) bool) Node {
+	root := &childField{nodes: []Node{node}, set: func([]Node) {}}
+	v := visitor{enter: enter, exit: exit}
+	v.visitField(nil, root)
+	if len(root.nodes) == 0 {
+		return nil
+	}
+	return root.nodes[0]
+}
+
+// Cursor describes a node encountered during Visit and allows the tree to
+// be changed in place around it
+type Cursor struct {
+	parent Node
+	field  *childField
+	index  int
+	// inserted counts the nodes inserted after the current node, which the
+	// traversal skips
+	inserted int
+}
+
+// Node returns the current node, or nil if it has been deleted
+func (c *Cursor) Node() Node {
+	if c.index < 0 || c.index >= len(c.field.nodes) {
+		return nil
+	}
+	return c.field.nodes[c.index]
+}
+
+// Parent returns the parent of the current node, or nil for the root
+func (c *Cursor) Parent() Node {
+	return c.parent
+}
+
+// Index returns the position of the current node among the children of its
+// parent that share a field, such as the inlines or the blocks of a list item
+func (c *Cursor) Index() int {
+	return c.index
+}
+
+// Siblings returns the children of the parent that share a field with the
+// current node, the current node included. The slice must not be modified.
+func (c *Cursor) Siblings() []Node {
+	return c.field.nodes
+}
+
+// Replace replaces the current node with node. The children of the new node
+// are visited instead of those of the old one when Replace is called in enter.
+// It panics if node cannot be stored where the current node is, such as a
+// paragraph in place of a list item.
+func (c *Cursor) Replace(node Node) {
+	if c.Node() == nil {
+		panic("parser: Replace called on a deleted node")
+	}
+	nodes := append([]Node{}, c.field.nodes...)
+	nodes[c.index] = node
+	c.field.update(nodes)
+}
+
+// Delete removes the current node from its parent
+func (c *Cursor) Delete() {
+	if c.parent == nil {
+		panic("parser: Delete called on the root node")
+	}
+	if c.Node() == nil {
+		panic("parser: Delete called on a deleted node")
+	}
+	nodes := append(append([]Node{}, c.field.nodes[:c.index]...), c.field.nodes[c.index+1:]...)
+	c.field.update(nodes)
+	c.index--
+}
+
+// InsertBefore inserts node before the current node. The inserted node is
+// not visited.
+func (c *Cursor) InsertBefore(node Node) {
+	c.insert(c.index, node)
+	c.index++
+}
+
+// InsertAfter inserts node after the current node. The inserted node is not
+// visited.
+func (c *Cursor) InsertAfter(node Node) {
+	c.insert(c.index+1, node)
+	c.inserted++
+}
+
+// insert stores node at index among the siblings of the current node
+func (c *Cursor) insert(index int, node Node) {
+	if c.parent == nil {
+		panic("parser: cannot insert next to the root node")
+	}
+	nodes := make([]Node, 0, len(c.field.nodes)+1)
+	nodes = append(nodes, c.field.nodes[:index]...)
+	nodes = append(nodes, node)
+	nodes = append(nodes, c.field.nodes[index:]...)
+	c.field.update(nodes)
+}
+
+// visitor holds the state of a Visit traversal
+type visitor struct {
+	enter, exit func(*Cursor) bool
+	stopped     bool
+}
+
+// visitField visits each node of a child field in order
+func (v *visitor) visitField(parent Node, field *childField) {
+	for index := 0; index < len(field.nodes) && !v.stopped; {
+		c := &Cursor{parent: parent, field: field, index: index}
+		v.visitNode(c)
+		index = c.index + c.inserted + 1
+	}
+}
+
+// visitNode calls the callbacks for the cursor's node and visits its children
+func (v *visitor) visitNode(c *Cursor) {
+	descend := true
+	if v.enter != nil {
+		descend = v.enter(c)
+	}
+	node := c.Node()
+	if node == nil {
+		return
+	}
+	if descend {
+		for _, field := range childFields(node) {
+			v.visitField(node, field)
+			if v.stopped {
+				return
+			}
+		}
+	}
+	if v.exit != nil && !v.exit(c) {
+		v.stopped = true
+	}
+}
+
+// childField is one list of children of a node, such as the inlines of a
+// paragraph or the rows of a table. set stores changed nodes back into the
+// parent and panics if a node has the wrong type.
+type childField struct {
+	nodes []Node
+	set   func([]Node)
+}
+
+// update stores nodes as the new content of the field
+func (f *childField) update(nodes []Node) {
+	f.set(nodes)
+	f.nodes = nodes
+}
+
+// childFields returns the lists of children of a node in document order
+func childFields(node Node) []*childField {
+	switch n := node.(type) {
+	case *Document:
+		return []*childField{
+			optionalField(n.FrontMatter, func(node *FrontMatter) { n.FrontMatter = node }),
+			nodeField(n.Children, func(nodes []Node) { n.Children = nodes }),
+		}
+	case *Blockquote:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *FootnoteDefinition:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Admonition:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *DefinitionList:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Term:
+		return []*childField{nodeField(n.Inlines, func(nodes []Node) { n.Inlines = nodes })}
+	case *Definition:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *JSX:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Heading:
+		return []*childField{nodeField(n.Inlines, func(nodes []Node) { n.Inlines = nodes })}
+	case *Paragraph:
+		return []*childField{nodeField(n.Inlines, func(nodes []Node) { n.Inlines = nodes })}
+	case *List:
+		return []*childField{typedField(n.Items, func(items []*ListItem) { n.Items = items })}
+	case *ListItem:
+		return []*childField{
+			nodeField(n.Inlines, func(nodes []Node) { n.Inlines = nodes }),
+			nodeField(n.Children, func(nodes []Node) { n.Children = nodes }),
+		}
+	case *Table:
+		return []*childField{
+			optionalField(n.Header, func(row *TableRow) { n.Header = row }),
+			typedField(n.Rows, func(rows []*TableRow) { n.Rows = rows }),
+		}
+	case *TableRow:
+		return []*childField{typedField(n.Cells, func(cells []*TableCell) { n.Cells = cells })}
+	case *TableCell:
+		return []*childField{nodeField(n.Inlines, func(nodes []Node) { n.Inlines = nodes })}
+	case *Emphasis:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Strong:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Strikethrough:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Link:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	case *Image:
+		return []*childField{nodeField(n.Children, func(nodes []Node) { n.Children = nodes })}
+	}
+	return nil
+}
+
+// nodeField returns a child field backed by a slice of nodes of any type
+func nodeField(nodes []Node, set func([]Node)) *childField {
+	return &childField{nodes: nodes, set: set}
+}
+
+// typedField returns a child field backed by a slice of one node type
+func typedField[T Node](typed []T, set func([]T)) *childField {
+	nodes := make([]Node, len(typed))
+	for i, node := range typed {
+		nodes[i] = node
+	}
+	return &childField{
+		nodes: nodes,
+		set: func(nodes []Node) {
+			typed := make([]T, len(nodes))
+			for i, node := range nodes {
+				typed[i] = nodeAs[T](node)
+			}
+			set(typed)
+		},
+	}
+}
+
+// optionalField returns a child field backed by a single node that may be nil
+func optionalField[T interface {
+	Node
+	comparable
+}](node T, set func(T)) *childField {
+	var zero T
+	field := &childField{set: func(nodes []Node) {
+		switch len(nodes) {
+		case 0:
+			set(zero)
+		case 1:
+			set(nodeAs[T](nodes[0]))
+		default:
+			panic(fmt.Sprintf("parser: cannot store %d nodes in place of a single %T", len(nodes), zero))
+		}
+	}}
+	if node != zero {
+		field.nodes = []Node{node}
+	}
+	return field
+}
+
+// nodeAs converts node to the type its field stores, panicking on a mismatch
+func nodeAs[T Node](node Node) T {
+	typed, ok := node.(T)
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("parser: cannot store %T in place of %T", node, zero))
+	}
+	return typed
+}
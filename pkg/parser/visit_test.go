@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const visitTestContent = `# Title
+
+> - Quoted *item*
+>   - Nested ` + "`code`" + `
+
+| A | B |
+|---|---|
+| 1 | **2** |
+`
+
+func parseVisitTestDocument(t *testing.T) *Document {
+	t.Helper()
+	doc, err := NewGoldmarkParser().Parse([]byte(visitTestContent))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return doc
+}
+
+func TestInspect(t *testing.T) {
+	doc := parseVisitTestDocument(t)
+
+	var types []string
+	Inspect(doc, func(node Node) bool {
+		types = append(types, NodeTypeString(node.Type()))
+		return true
+	})
+
+	expected := "Document Heading Text Blockquote List ListItem Text Emphasis Text List ListItem Text CodeSpan " +
+		"Table TableRow TableCell Text TableCell Text TableRow TableCell Text TableCell Strong Text"
+	if got := strings.Join(types, " "); got != expected {
+		t.Errorf("Expected nodes\n%s\ngot\n%s", expected, got)
+	}
+
+	types = nil
+	Inspect(doc, func(node Node) bool {
+		types = append(types, NodeTypeString(node.Type()))
+		return node.Type() != NodeBlockquote && node.Type() != NodeTable
+	})
+	if got := strings.Join(types, " "); got != "Document Heading Text Blockquote Table" {
+		t.Errorf("Expected skipped children, got %s", got)
+	}
+}
+
+func TestFindNodesNested(t *testing.T) {
+	doc := parseVisitTestDocument(t)
+
+	if lists := FindNodes(doc, NodeList); len(lists) != 2 {
+		t.Errorf("Expected 2 lists, got %d", len(lists))
+	}
+	if code := FindFirstNode(doc, NodeCodeSpan); code == nil || code.(*CodeSpan).Content != "code" {
+		t.Errorf("Expected nested code span, got %v", code)
+	}
+	if len(doc.GetAllNodes()) != 24 {
+		t.Errorf("Expected 24 nodes below the document, got %d", len(doc.GetAllNodes()))
+	}
+}
+
+func TestVisitParentsAndExit(t *testing.T) {
+	doc := parseVisitTestDocument(t)
+
+	var depth, maxDepth int
+	var exits []string
+	Visit(doc, func(c *Cursor) bool {
+		if c.Node() == doc {
+			if c.Parent() != nil {
+				t.Errorf("Expected no parent for the document, got %v", c.Parent())
+			}
+		} else if c.Parent() == nil {
+			t.Errorf("Expected a parent for %v", c.Node())
+		}
+		if cell, ok := c.Node().(*TableCell); ok {
+			row := c.Parent().(*TableRow)
+			if row.Cells[c.Index()] != cell {
+				t.Errorf("Expected cell at index %d of its row", c.Index())
+			}
+		}
+		depth++
+		maxDepth = max(maxDepth, depth)
+		return true
+	}, func(c *Cursor) bool {
+		depth--
+		if c.Node().Type() == NodeList {
+			exits = append(exits, c.Node().(*List).Items[0].Text())
+		}
+		return true
+	})
+
+	if depth != 0 || maxDepth != 7 {
+		t.Errorf("Expected balanced enter and exit with depth 7, got %d and %d", depth, maxDepth)
+	}
+	// The nested list is left before its parent
+	if strings.Join(exits, "|") != "Nested code|Quoted item" {
+		t.Errorf("Expected inner list to exit first, got %v", exits)
+	}
+
+	var visited int
+	Visit(doc, nil, func(c *Cursor) bool {
+		visited++
+		return c.Node().Type() != NodeEmphasis
+	})
+	if visited != 5 {
+		t.Errorf("Expected traversal to stop after 5 nodes, got %d", visited)
+	}
+}
+
+func TestVisitMutation(t *testing.T) {
+	doc := parseVisitTestDocument(t)
+
+	var visited []string
+	Visit(doc, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *Heading:
+			c.InsertAfter(&ThematicBreak{Marker: "---"})
+			c.InsertBefore(&Paragraph{Inlines: []Node{&Text{Content: "Intro"}}})
+		case *Emphasis:
+			c.Replace(&Strong{Marker: "**", Children: n.Children})
+		case *CodeSpan:
+			c.Delete()
+		case *TableRow:
+			if c.Index() == 0 && c.Parent().(*Table).Header != n {
+				c.Delete()
+			}
+		case *Text:
+			visited = append(visited, n.Content)
+		}
+		return true
+	}, nil)
+
+	if len(doc.Children) != 5 {
+		t.Fatalf("Expected 5 blocks, got %v", doc.Children)
+	}
+	if doc.Children[0].Type() != NodeParagraph || doc.Children[2].Type() != NodeThematicBreak {
+		t.Errorf("Expected inserted paragraph and thematic break, got %v", doc.Children)
+	}
+
+	item := doc.Children[3].(*Blockquote).Children[0].(*List).Items[0]
+	if _, ok := item.Inlines[1].(*Strong); !ok {
+		t.Errorf("Expected emphasis replaced by strong, got %T", item.Inlines[1])
+	}
+	nested := item.Children[0].(*List).Items[0]
+	if len(nested.Inlines) != 1 {
+		t.Errorf("Expected code span removed, got %v", nested.Inlines)
+	}
+
+	table := doc.Children[4].(*Table)
+	if table.Header == nil || len(table.Rows) != 0 {
+		t.Errorf("Expected header kept and body row removed, got %v and %v", table.Header, table.Rows)
+	}
+
+	// Inserted nodes are not visited, the children of replacements are
+	expected := "Title|Quoted |item|Nested |A|B"
+	if got := strings.Join(visited, "|"); got != expected {
+		t.Errorf("Expected visited text %q, got %q", expected, got)
+	}
+}
+
+func TestVisitReplaceRoot(t *testing.T) {
+	paragraph := &Paragraph{Inlines: []Node{&Text{Content: "text"}}}
+	heading := &Heading{Level: 1, Inlines: paragraph.Inlines}
+
+	root := Visit(paragraph, func(c *Cursor) bool {
+		if c.Parent() == nil {
+			c.Replace(heading)
+		}
+		return true
+	}, nil)
+	if root != heading {
+		t.Errorf("Expected replaced root, got %v", root)
+	}
+}
+
+func TestVisitReplaceWrongType(t *testing.T) {
+	list := &List{Items: []*ListItem{{Inlines: []Node{&Text{Content: "item"}}}}}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic when replacing a list item with a paragraph")
+		}
+	}()
+	Visit(list, func(c *Cursor) bool {
+		if _, ok := c.Node().(*ListItem); ok {
+			c.Replace(&Paragraph{})
+		}
+		return true
+	}, nil)
+}
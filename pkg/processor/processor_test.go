@@ -33,6 +33,7 @@ func TestIsMarkdownFile(t *testing.T) {
 		{"README.md", true},
 		{"doc.markdown", true},
 		{"file.mdown", true},
+		{"page.mdx", true},
 		{"script.js", false},
 		{"style.css", false},
 		{"README.MD", true},
@@ -0,0 +1,178 @@
+package renderer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Text nodes hold inline content as the author wrote it, including backslash
+// escapes and entity references, so it is written back unchanged. Escapes are
+// only added where the renderer puts text in a new position, such as at the
+// start of a line after wrapping, where it could be read as block markup.
+
+var (
+	// blockMarkerWord matches a word that would open a heading, bullet list
+	// item, thematic break or definition, or underline a setext heading, at
+	// the start of a paragraph continuation line
+	blockMarkerWord = regexp.MustCompile(`^(#{1,6}|\+|-+|=+|:)$`)
+	// emphasisRunWord matches a run of emphasis characters standing alone,
+	// which is either a thematic break or a bullet at the start of a line
+	emphasisRunWord = regexp.MustCompile(`^(\*+|_+)$`)
+	// orderedMarkerWord matches a word that would open an ordered list. Only
+	// lists starting at 1 can interrupt a paragraph.
+	orderedMarkerWord = regexp.MustCompile(`^0{0,8}1[.)]$`)
+	// anyOrderedMarkerWord matches a word that would open an ordered list at
+	// the start of a paragraph, where the list may start at any number
+	anyOrderedMarkerWord = regexp.MustCompile(`^[0-9]{1,9}[.)]$`)
+	// codeFenceStart matches the start of a line that would open a code fence
+	codeFenceStart = regexp.MustCompile("^(`{3,}[^`]*$|~{3,})")
+	// htmlBlockStart matches the start of an HTML block that can interrupt a paragraph
+	htmlBlockStart = regexp.MustCompile(`(?i)^(<(script|pre|style|textarea)(\s|>|$)|<!--|<\?|<![a-z]|<!\[CDATA\[|` +
+		`</?(address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|` +
+		`dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|` +
+		`html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|` +
+		`section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(\s|/?>|$))`)
+	// taskCheckBoxStart matches list item text that would be read as a task check box
+	taskCheckBoxStart = regexp.MustCompile(`^\[[\sxX]\]`)
+	// closingHashes matches a trailing run of hashes that would close an ATX heading
+	closingHashes = regexp.MustCompile(`(^|[ \t])#+[ \t]*$`)
+	// alertMarkerStart matches a GitHub alert marker and the space after it
+	// at the start of a paragraph. The marker must not be left alone on the
+	// first line, which would turn a blockquote into an alert.
+	alertMarkerStart = regexp.MustCompile(`^\[![\w-]+\] `)
+)
+
+// escapeContinuationLines escapes the first word of each line after the
+// first one, which would otherwise interrupt the paragraph with a new block
+func escapeContinuationLines(text string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		word, rest, found := strings.Cut(lines[i], " ")
+		lines[i] = escapeLineStart(word)
+		if found {
+			lines[i] += " " + rest
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeLineStart escapes a word that starts a paragraph continuation line
+// if it would open a heading, list, block quote, thematic break or
+// definition there.
+// The escape only depends on the word, so that wrapping can account for it.
+func escapeLineStart(word string) string {
+	switch {
+	case blockMarkerWord.MatchString(word), strings.HasPrefix(word, ">"):
+		return `\` + word
+	case emphasisRunWord.MatchString(word):
+		// Escaping only the first character would leave a shorter run
+		// that could pair up with other delimiters in the paragraph
+		return strings.NewReplacer("*", `\*`, "_", `\_`).Replace(word)
+	case orderedMarkerWord.MatchString(word):
+		return word[:len(word)-1] + `\` + word[len(word)-1:]
+	}
+	return word
+}
+
+// escapeParagraphStart escapes the first word of a line that starts a
+// paragraph, such as the text of a setext heading, if it would open another
+// block there. Unlike on continuation lines, any ordered list marker and code
+// fences count; rest is the remainder of the line.
+func escapeParagraphStart(word, rest string) string {
+	switch {
+	case anyOrderedMarkerWord.MatchString(word):
+		return word[:len(word)-1] + `\` + word[len(word)-1:]
+	case codeFenceStart.MatchString(word + rest):
+		// Every fence character is escaped so that no shorter run is left
+		info := strings.TrimLeft(word, word[:1])
+		return strings.Repeat(`\`+word[:1], len(word)-len(info)) + info
+	}
+	return escapeLineStart(word)
+}
+
+// keepOnLine reports whether a word must not start a new line when wrapping
+// because it would open an HTML block or a code fence there. An escape would
+// turn the inline HTML into text, and split the fence characters into
+// shorter runs that could pair up as code spans or strikethrough.
+func keepOnLine(word string) bool {
+	if strings.HasPrefix(word, "```") || strings.HasPrefix(word, "~~~") {
+		return true
+	}
+	return strings.HasPrefix(word, "<") &&
+		htmlBlockStart.MatchString(strings.ReplaceAll(word, unbreakableSpace, " "))
+}
+
+// escapeTaskCheckBox escapes the text of a list item that is not a task but
+// starts like a task check box
+func escapeTaskCheckBox(text string) string {
+	if taskCheckBoxStart.MatchString(text) {
+		return `\` + text
+	}
+	return text
+}
+
+// escapeHeadingText escapes heading text that would be read as other syntax:
+// hashes that close an ATX heading, markers at the start of the lines of a
+// setext heading that would open another block, or, if braces is set, a
+// brace group that would be parsed as the attribute block
+func escapeHeadingText(text string, atx, braces bool) string {
+	if atx {
+		if match := closingHashes.FindStringIndex(text); match != nil {
+			hashes := strings.IndexByte(text[match[0]:], '#') + match[0]
+			text = text[:hashes] + `\` + text[hashes:]
+		}
+	} else if text != "" {
+		line, _, _ := strings.Cut(text, "\n")
+		word, _, _ := strings.Cut(line, " ")
+		text = escapeParagraphStart(word, line[len(word):]) + escapeContinuationLines(text[len(word):])
+	}
+
+	if braces && strings.HasSuffix(strings.TrimRight(text, " \t"), "}") {
+		if open := lastUnescaped(text, '{'); open >= 0 {
+			text = text[:open] + `\` + text[open:]
+		}
+	}
+	return text
+}
+
+// lastUnescaped returns the index of the last occurrence of c in text that is
+// not escaped with a backslash, or -1
+func lastUnescaped(text string, c byte) int {
+	last := -1
+	escaped := false
+	for i := 0; i < len(text); i++ {
+		if text[i] == c && !escaped {
+			last = i
+		}
+		escaped = text[i] == '\\' && !escaped
+	}
+	return last
+}
+
+// escapeTablePipes escapes pipes in cell content that are not already escaped
+func escapeTablePipes(text string) string {
+	return escapeUnescaped(text, TablePipe)
+}
+
+// escapeUnescaped adds a backslash before each of the given characters in
+// text that is not already escaped
+func escapeUnescaped(text, chars string) string {
+	if !strings.ContainsAny(text, chars) {
+		return text
+	}
+
+	var sb strings.Builder
+	escaped := false
+	for _, ch := range text {
+		if !escaped && strings.ContainsRune(chars, ch) {
+			sb.WriteRune('\\')
+		}
+		escaped = ch == '\\' && !escaped
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+package renderer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmparser "github.com/yuin/goldmark/parser"
+)
+
+// toHTML converts GFM with heading attributes to HTML, to check that escaped
+// output reads back as the same content
+func toHTML(t *testing.T, markdown string) string {
+	t.Helper()
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(gmparser.WithAttribute()),
+	)
+	var html bytes.Buffer
+	if err := md.Convert([]byte(markdown), &html); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	return html.String()
+}
+
+func TestEscapeLineStart(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+		html     string
+	}{
+		{"-", `\-`, "-"},
+		{"+", `\+`, "+"},
+		{"#", `\#`, "#"},
+		{"######", `\######`, "######"},
+		{"#######", "#######", "#######"},
+		{"===", `\===`, "==="},
+		{"---", `\---`, "---"},
+		{":", `\:`, ":"},
+		{">", `\>`, "&gt;"},
+		{">quote", `\>quote`, "&gt;quote"},
+		{"*", `\*`, "*"},
+		{"***", `\*\*\*`, "***"},
+		{"__", `\_\_`, "__"},
+		{"1.", `1\.`, "1."},
+		{"01)", `01\)`, "01)"},
+		{"2.", "2.", "2."},
+		{"-x", "-x", "-x"},
+		{"word", "word", "word"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got := escapeLineStart(tt.word)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+
+			expected := "<p>Some text\n" + tt.html + " more words</p>\n"
+			if html := toHTML(t, "Some text\n"+got+" more words\n"); html != expected {
+				t.Errorf("Expected %q to stay in the paragraph, got %q", got, html)
+			}
+		})
+	}
+}
+
+func TestKeepOnLine(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected bool
+	}{
+		{"```", true},
+		{"```go", true},
+		{"~~~", true},
+		{"<div>", true},
+		{"<div", true},
+		{"</table>", true},
+		{"<!--", true},
+		{"<span>", false},
+		{"<x-widget>", false},
+		{"`code`", false},
+		{"~~gone~~", false},
+		{"word", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := keepOnLine(tt.word); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+
+			// Words that must stay on their line would end the paragraph there
+			html := toHTML(t, "Some text\n"+tt.word+" more words\n")
+			paragraph := strings.HasPrefix(html, "<p>Some text\n") && strings.Count(html, "<p>") == 1 &&
+				strings.HasSuffix(html, "</p>\n")
+			if paragraph == tt.expected {
+				t.Errorf("Expected a line starting with %q to end the paragraph: %t, got %q", tt.word, tt.expected, html)
+			}
+		})
+	}
+}
+
+func TestEscapeHeadingText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		atx      bool
+		braces   bool
+		expected string
+		html     string
+	}{
+		{"plain atx", "Title", true, true, "Title", "Title"},
+		{"closing hashes", "Title #", true, false, `Title \#`, "Title #"},
+		{"only hashes", "##", true, false, `\##`, "##"},
+		{"hash in word", "C#", true, false, "C#", "C#"},
+		{"attribute block", "Title {x}", true, true, `Title \{x}`, "Title {x}"},
+		{"braces without attributes", "Title {x}", true, false, "Title {x}", "Title {x}"},
+		{"escaped braces", `Title \{x}`, true, true, `Title \{x}`, "Title {x}"},
+		{"plain setext", "Title", false, false, "Title", "Title"},
+		{"bullet", "- item", false, false, `\- item`, "- item"},
+		{"plus", "+ item", false, false, `\+ item`, "+ item"},
+		{"blockquote", "> q", false, false, `\> q`, "&gt; q"},
+		{"atx heading", "## h", false, false, `\## h`, "## h"},
+		{"thematic break", "***", false, false, `\*\*\*`, "***"},
+		{"ordered list", "2. two", false, false, `2\. two`, "2. two"},
+		{"ordered list paren", "1) one", false, false, `1\) one`, "1) one"},
+		{"backtick fence", "```x", false, false, "\\`\\`\\`x", "```x"},
+		{"tilde fence", "~~~ t", false, false, `\~\~\~ t`, "~~~ t"},
+		{"code span", "`` `c` `` and", false, false, "`` `c` `` and", "<code>`c`</code> and"},
+		{"setext attribute block", "Title {x}", false, true, `Title \{x}`, "Title {x}"},
+		{"hard break", "a\\\n- b", false, false, "a\\\n\\- b", "a<br>\n- b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeHeadingText(tt.text, tt.atx, tt.braces)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+
+			markdown := got + "\n===\n"
+			if tt.atx {
+				markdown = "# " + got + "\n"
+			}
+			expected := "<h1>" + tt.html + "</h1>\n"
+			if html := toHTML(t, markdown); html != expected {
+				t.Errorf("Expected %q to read back as %q, got %q", markdown, expected, html)
+			}
+		})
+	}
+}
+
+func TestEscapeTablePipes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		html     string
+	}{
+		{"plain", "a b", "a b", "a b"},
+		{"pipe", "a|b", `a\|b`, "a|b"},
+		{"escaped pipe", `a\|b`, `a\|b`, "a|b"},
+		{"code span", "`a|b`", "`a\\|b`", "<code>a|b</code>"},
+		{"several pipes", "|a|", `\|a\|`, "|a|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeTablePipes(tt.text)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+
+			html := toHTML(t, "| h | x |\n|---|---|\n| "+got+" | y |\n")
+			if !strings.Contains(html, "<td>"+tt.html+"</td>\n<td>y</td>") {
+				t.Errorf("Expected cell %q to read back as %q, got %q", got, tt.html, html)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+const (
+	// unbreakableSpace stands in for spaces that line wrapping must not break
+	// at, such as those inside link text or code spans
+	unbreakableSpace = "\x00"
+	// verbatimLineBreak stands in for line breaks inside inline math, which
+	// are kept as written and must not be taken for the start of a new line
+	// when wrapping or escaping
+	verbatimLineBreak = "\x01"
+	// codeSpanDelimiter is the character that delimits inline code
+	codeSpanDelimiter = "`"
+	// strikethroughMarker delimits GFM strikethrough text
+	strikethroughMarker = "~~"
+	// defaultHardBreak is rendered for hard line breaks without a marker
+	defaultHardBreak = "\\"
+)
+
+// sentinels turns the stand-ins used while wrapping back into the
+// characters they replace
+var sentinels = strings.NewReplacer(unbreakableSpace, " ", verbatimLineBreak, "\n")
+
+// renderInlines serializes inline nodes to markdown. When reflow is set, soft
+// breaks become spaces so that the text can be wrapped again, and spaces
+// inside links, images, code spans and inline HTML are marked unbreakable.
+func (r *MarkdownRenderer) renderInlines(nodes []parser.Node, reflow bool) string {
+	var sb strings.Builder
+	r.writeInlines(&sb, nodes, reflow)
+	return sb.String()
+}
+
+// renderInlineLine renders inline content that has to stay on one line, as
+// in headings, list items and table cells
+func (r *MarkdownRenderer) renderInlineLine(nodes []parser.Node) string {
+	return sentinels.Replace(r.renderInlines(nodes, true))
+}
+
+// wrapInlines renders inline content wrapped at the given width. Without a
+// width, soft breaks are kept as the author wrote them.
+func (r *MarkdownRenderer) wrapInlines(nodes []parser.Node, width int) string {
+	if width <= 0 {
+		return sentinels.Replace(escapeContinuationLines(r.renderInlines(nodes, false)))
+	}
+
+	// Hard breaks are the only line endings left when reflowing
+	lines := strings.Split(r.renderInlines(nodes, true), "\n")
+	if marker := alertMarkerStart.FindStringIndex(lines[0]); marker != nil {
+		lines[0] = lines[0][:marker[1]-1] + unbreakableSpace + lines[0][marker[1]:]
+	}
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width, i > 0)
+	}
+	return sentinels.Replace(strings.Join(lines, "\n"))
+}
+
+// wrapLine breaks a line at spaces so that no line exceeds width, unless a
+// single word is longer than that or must stay on the previous line. The
+// first word of every line but the first of the paragraph is escaped where
+// it would start a new block; continued tells whether line itself follows
+// a hard break.
+func wrapLine(line string, width int, continued bool) string {
+	var sb strings.Builder
+	lineLength := 0
+
+	for _, word := range strings.Split(line, " ") {
+		if word == "" {
+			continue
+		}
+
+		length := displayWidth(word)
+		if lineLength > 0 && lineLength+1+length > width && !keepOnLine(word) {
+			sb.WriteString("\n")
+			lineLength = 0
+			continued = true
+		}
+		switch {
+		case lineLength == 0 && continued:
+			word = escapeLineStart(word)
+			length = displayWidth(word)
+		case lineLength > 0:
+			sb.WriteString(" ")
+			lineLength++
+		}
+		sb.WriteString(word)
+		lineLength += length
+	}
+
+	return sb.String()
+}
+
+// writeInlines writes each inline node to sb
+func (r *MarkdownRenderer) writeInlines(sb *strings.Builder, nodes []parser.Node, reflow bool) {
+	for _, node := range nodes {
+		r.writeInline(sb, node, reflow)
+	}
+}
+
+// writeInline writes a single inline node as markdown
+func (r *MarkdownRenderer) writeInline(sb *strings.Builder, node parser.Node, reflow bool) {
+	switch n := node.(type) {
+	case *parser.Text:
+		sb.WriteString(n.Content)
+	case *parser.SoftBreak:
+		if reflow {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString("\n")
+		}
+	case *parser.HardBreak:
+		marker := n.Marker
+		if marker == "" {
+			marker = defaultHardBreak
+		}
+		// Trailing spaces must survive wrapping as part of the last word
+		sb.WriteString(unbreakable(marker, reflow))
+		sb.WriteString("\n")
+	case *parser.Emphasis:
+		sb.WriteString(n.Marker)
+		r.writeInlines(sb, n.Children, reflow)
+		sb.WriteString(n.Marker)
+	case *parser.Strong:
+		sb.WriteString(n.Marker)
+		r.writeInlines(sb, n.Children, reflow)
+		sb.WriteString(n.Marker)
+	case *parser.Strikethrough:
+		sb.WriteString(strikethroughMarker)
+		r.writeInlines(sb, n.Children, reflow)
+		sb.WriteString(strikethroughMarker)
+	case *parser.CodeSpan:
+		sb.WriteString(unbreakable(codeSpan(n.Content), reflow))
+	case *parser.Link:
+		link := "[" + r.renderInlines(n.Children, reflow) + "]" +
+			r.linkTail(n.Reference, n.Label, n.Destination, n.Title)
+		sb.WriteString(unbreakable(link, reflow))
+	case *parser.Image:
+		image := "![" + r.renderInlines(n.Children, reflow) + "]" +
+			r.linkTail(n.Reference, n.Label, n.Destination, n.Title)
+		sb.WriteString(unbreakable(image, reflow))
+	case *parser.Autolink:
+		// URLs have no spaces, so wrapping always keeps them whole
+		if n.Bare {
+			sb.WriteString(n.URL)
+		} else {
+			sb.WriteString("<" + n.URL + ">")
+		}
+	case *parser.RawHTML:
+		sb.WriteString(unbreakable(n.Content, reflow))
+	case *parser.InlineMath:
+		// Formulas are written as they are, on the lines the author chose
+		math := strings.ReplaceAll(n.Content, "\n", verbatimLineBreak)
+		sb.WriteString(unbreakable(math, reflow))
+	case *parser.FootnoteReference:
+		sb.WriteString("[^" + n.Label + "]")
+	}
+}
+
+// unbreakable marks the spaces of s as unbreakable when text is reflowed
+func unbreakable(s string, reflow bool) string {
+	if !reflow {
+		return s
+	}
+	return strings.ReplaceAll(s, " ", unbreakableSpace)
+}
+
+// codeSpan delimits inline code with a backtick run longer than any inside
+// it, padding the content where CommonMark would otherwise strip or merge it
+func codeSpan(content string) string {
+	longest, run := 0, 0
+	for _, c := range content {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat(codeSpanDelimiter, longest+1)
+
+	padded := strings.HasPrefix(content, codeSpanDelimiter) || strings.HasSuffix(content, codeSpanDelimiter) ||
+		(strings.HasPrefix(content, " ") && strings.HasSuffix(content, " ") && strings.Trim(content, " ") != "")
+	if padded {
+		content = " " + content + " "
+	}
+	return fence + content + fence
+}
+
+// linkTail returns what follows the text of a link or image: the reference
+// label or the inline destination and title
+func (r *MarkdownRenderer) linkTail(reference parser.ReferenceStyle, label, destination, title string) string {
+	switch reference {
+	case parser.ReferenceFull:
+		return "[" + label + "]"
+	case parser.ReferenceCollapsed:
+		return "[]"
+	case parser.ReferenceShortcut:
+		return ""
+	}
+
+	if destination == "" && title == "" {
+		return "()"
+	}
+	destination = linkDestination(destination)
+	if title != "" {
+		destination += " " + r.quoteTitle(title)
+	}
+	return "(" + destination + ")"
+}
+
+// linkDestination wraps a destination in angle brackets when it could not
+// be written bare: when it is empty, starts with an angle bracket, or holds
+// spaces, control characters or unbalanced parentheses. Angle brackets
+// inside it are escaped.
+func linkDestination(destination string) string {
+	bare := destination != "" && !strings.HasPrefix(destination, "<") && balancedParentheses(destination) &&
+		strings.IndexFunc(destination, func(c rune) bool { return c <= ' ' || c == '\x7f' }) < 0
+	if bare {
+		return destination
+	}
+	return "<" + escapeUnescaped(destination, "<>") + ">"
+}
+
+// balancedParentheses reports whether every unescaped parenthesis in s is matched
+func balancedParentheses(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// quoteTitle wraps a link title in the configured quotes, escaping the
+// quote characters inside it. Line breaks become spaces, since the link is
+// written on one line.
+func (r *MarkdownRenderer) quoteTitle(title string) string {
+	title = strings.ReplaceAll(title, "\n", " ")
+	switch r.config.Links.TitleQuote {
+	case config.TitleQuoteSingle:
+		return "'" + escapeUnescaped(title, "'") + "'"
+	case config.TitleQuoteParentheses:
+		return "(" + escapeUnescaped(title, "()") + ")"
+	default:
+		return `"` + escapeUnescaped(title, `"`) + `"`
+	}
+}
+
+// linkReferenceDefinition returns a link reference definition as a single line
+func (r *MarkdownRenderer) linkReferenceDefinition(definition *parser.LinkReferenceDefinition) string {
+	markdown := "[" + definition.Label + "]: " + linkDestination(definition.Destination)
+	if definition.Title != "" {
+		markdown += " " + r.quoteTitle(definition.Title)
+	}
+	return markdown
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/Gosayram/go-mdfmt/pkg/config"
 	"github.com/Gosayram/go-mdfmt/pkg/parser"
@@ -13,8 +14,46 @@ import (
 const (
 	// SecondHeadingLevel represents heading level 2
 	SecondHeadingLevel = 2
+	// BlockquotePrefix is written before every non-empty line of a blockquote
+	BlockquotePrefix = "> "
+	// BlockquoteEmptyPrefix is written for empty lines inside a blockquote
+	BlockquoteEmptyPrefix = ">"
+	// MinNestedLineWidth is the smallest line width used for content nested in containers
+	MinNestedLineWidth = 20
+	// MinTableDelimiterWidth is the minimum number of dashes in an aligned delimiter cell
+	MinTableDelimiterWidth = 3
+	// TablePipe separates table cells
+	TablePipe = "|"
+	// FootnoteIndent indents the continuation lines of footnote definitions
+	FootnoteIndent = "    "
+	// AdmonitionIndent indents the body of MkDocs admonitions
+	AdmonitionIndent = "    "
+	// DefinitionMarker starts each definition of a definition list
+	DefinitionMarker = ": "
+	// DefinitionIndent indents the continuation lines of definitions
+	DefinitionIndent = "  "
+	// DefaultThematicBreak is rendered for thematic breaks without a marker
+	DefaultThematicBreak = "---"
+	// DashBreakCharacter replaces the asterisks of a thematic break that
+	// starts a list item with a * bullet
+	DashBreakCharacter = "-"
+	// AsteriskBreakCharacter replaces the dashes of a thematic break that
+	// starts a list item with a - bullet
+	AsteriskBreakCharacter = "*"
+	// UncheckedTaskBox is written at the start of open task list items
+	UncheckedTaskBox = "[ ]"
+	// CheckedTaskBox is written at the start of completed task list items
+	CheckedTaskBox = "[x]"
+	// ListEnd is written between a list and an indented code block after it,
+	// which would otherwise continue the last list item
+	ListEnd = "<!-- -->"
+	// centerPaddingDivisor splits padding evenly on both sides of centered cells
+	centerPaddingDivisor = 2
 )
 
+// bareAttributeValue matches attribute values that need no quotes: words and numbers
+var bareAttributeValue = regexp.MustCompile(`^([A-Za-z_:][A-Za-z0-9_:.-]*|-?[0-9]+(\.[0-9]+)?)$`)
+
 // Renderer represents a renderer that converts AST back to markdown
 type Renderer interface {
 	// Render renders the AST to markdown
@@ -27,6 +66,17 @@ type Renderer interface {
 type MarkdownRenderer struct {
 	output strings.Builder
 	config *config.Config
+	// verbatim records output ranges that whitespace normalization must not touch
+	verbatim []outputSpan
+	// inDefinitions is set while consecutive link reference definitions are
+	// being written, which are kept on adjacent lines
+	inDefinitions bool
+}
+
+// outputSpan is a byte range [start, end) of the rendered output
+type outputSpan struct {
+	start int
+	end   int
 }
 
 // New creates a new markdown renderer
@@ -38,6 +88,8 @@ func New() *MarkdownRenderer {
 func (r *MarkdownRenderer) Render(doc *parser.Document, cfg *config.Config) (string, error) {
 	r.output.Reset()
 	r.config = cfg
+	r.verbatim = nil
+	r.inDefinitions = false
 
 	if err := r.renderDocument(doc, 0); err != nil {
 		return "", err
@@ -67,18 +119,72 @@ func (r *MarkdownRenderer) RenderTo(w io.Writer, doc *parser.Document, cfg *conf
 	return err
 }
 
-// renderDocument renders a document node
+// renderDocument renders a document node. Footnote definitions are collected
+// from the whole document and rendered after all other content.
 func (r *MarkdownRenderer) renderDocument(doc *parser.Document, depth int) error {
-	for _, child := range doc.Children {
-		if err := r.renderNode(child, depth); err != nil {
+	if doc.FrontMatter != nil {
+		r.renderFrontMatter(doc.FrontMatter)
+	}
+
+	if err := r.renderBlocks(doc.Children, depth); err != nil {
+		return err
+	}
+
+	for _, definition := range collectFootnoteDefinitions(doc) {
+		r.endDefinitions()
+		if err := r.renderFootnoteDefinition(definition, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderBlocks renders sibling blocks in order. Footnote definitions are
+// skipped, as they are rendered at the end of the document.
+func (r *MarkdownRenderer) renderBlocks(blocks []parser.Node, depth int) error {
+	var previous parser.Node
+	for _, block := range blocks {
+		if block.Type() == parser.NodeFootnoteDefinition {
+			continue
+		}
+		if endsList(previous, block) {
+			r.output.WriteString(ListEnd + "\n\n")
+		}
+		if err := r.renderNode(block, depth); err != nil {
 			return err
 		}
+		previous = block
 	}
 	return nil
 }
 
+// endsList reports whether block must be separated from the block before it
+// by ListEnd: an indented code block after a list would be read as part of
+// the list's last item
+func endsList(previous, block parser.Node) bool {
+	code, ok := block.(*parser.CodeBlock)
+	return ok && !code.Fenced && previous != nil && previous.Type() == parser.NodeList
+}
+
+// collectFootnoteDefinitions returns all footnote definitions in document
+// order, wherever they are nested
+func collectFootnoteDefinitions(doc *parser.Document) []*parser.FootnoteDefinition {
+	var definitions []*parser.FootnoteDefinition
+	parser.Inspect(doc, func(node parser.Node) bool {
+		if definition, ok := node.(*parser.FootnoteDefinition); ok {
+			definitions = append(definitions, definition)
+		}
+		return true
+	})
+	return definitions
+}
+
 // renderNode renders a single node
 func (r *MarkdownRenderer) renderNode(node parser.Node, depth int) error {
+	if node.Type() != parser.NodeLinkReferenceDefinition {
+		r.endDefinitions()
+	}
+
 	switch n := node.(type) {
 	case *parser.Heading:
 		return r.renderHeading(n, depth)
@@ -87,11 +193,31 @@ func (r *MarkdownRenderer) renderNode(node parser.Node, depth int) error {
 	case *parser.List:
 		return r.renderList(n, depth)
 	case *parser.ListItem:
-		return r.renderListItem(n, depth)
+		return r.renderListItem(n, false)
 	case *parser.CodeBlock:
 		return r.renderCodeBlock(n, depth)
 	case *parser.Text:
 		return r.renderText(n, depth)
+	case *parser.Blockquote:
+		return r.renderBlockquote(n, depth)
+	case *parser.Table:
+		return r.renderTable(n, depth)
+	case *parser.ThematicBreak:
+		return r.renderThematicBreak(n, depth)
+	case *parser.HTMLBlock:
+		return r.renderHTMLBlock(n, depth)
+	case *parser.MathBlock:
+		return r.renderMathBlock(n, depth)
+	case *parser.Admonition:
+		return r.renderAdmonition(n, depth)
+	case *parser.DefinitionList:
+		return r.renderDefinitionList(n, depth)
+	case *parser.ESM:
+		return r.renderESM(n, depth)
+	case *parser.JSX:
+		return r.renderJSX(n, depth)
+	case *parser.LinkReferenceDefinition:
+		return r.renderLinkReferenceDefinition(n, depth)
 	default:
 		// Unknown node type, skip
 		return nil
@@ -100,9 +226,20 @@ func (r *MarkdownRenderer) renderNode(node parser.Node, depth int) error {
 
 // renderHeading renders a heading node
 func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
-	if heading.Style == "setext" && heading.Level <= SecondHeadingLevel {
+	text := r.renderInlineLine(heading.Inlines)
+	attributes := headingAttributes(heading)
+	setext := heading.Style == parser.HeadingStyleSetext && heading.Level <= SecondHeadingLevel
+	if attributes == "" {
+		braces := r.config.Markdown.HasExtension(config.ExtensionHeadingAttributes) && !endsWithRawHTML(heading.Inlines)
+		text = escapeHeadingText(text, !setext, braces)
+	}
+
+	if setext {
 		// Setext-style heading
-		r.output.WriteString(heading.Text)
+		if attributes != "" {
+			text += " " + attributes
+		}
+		r.output.WriteString(text)
 		r.output.WriteString("\n")
 
 		marker := "="
@@ -110,7 +247,7 @@ func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
 			marker = "-"
 		}
 
-		textLength := len(strings.TrimSpace(heading.Text))
+		textLength := displayWidth(strings.TrimSpace(text))
 		if textLength == 0 {
 			textLength = 3 // minimum length
 		}
@@ -119,94 +256,79 @@ func (r *MarkdownRenderer) renderHeading(heading *parser.Heading, _ int) error {
 		r.output.WriteString("\n\n")
 	} else {
 		// ATX-style heading
-		r.output.WriteString(strings.Repeat("#", heading.Level))
-		r.output.WriteString(" ")
-		r.output.WriteString(heading.Text)
+		hashes := strings.Repeat("#", heading.Level)
+		r.output.WriteString(hashes)
+		if text != "" {
+			r.output.WriteString(" ")
+			r.output.WriteString(text)
+		}
+		if heading.Style == parser.HeadingStyleATXClosed {
+			r.output.WriteString(" ")
+			r.output.WriteString(hashes)
+		}
+		if attributes != "" && text != "" {
+			r.output.WriteString(" ")
+			r.output.WriteString(attributes)
+		}
 		r.output.WriteString("\n\n")
 	}
 
 	return nil
 }
 
-// renderParagraph renders a paragraph node
-func (r *MarkdownRenderer) renderParagraph(para *parser.Paragraph, _ int) error {
-	content := para.Text
+// endsWithRawHTML reports whether the last inline is raw HTML. A trailing
+// brace group is then an MDX expression rather than text that could be read
+// as an attribute block.
+func endsWithRawHTML(inlines []parser.Node) bool {
+	return len(inlines) > 0 && inlines[len(inlines)-1].Type() == parser.NodeRawHTML
+}
 
-	// Fix broken markdown links first
-	content = r.fixBrokenLinks(content)
+// headingAttributes returns the attribute block of a heading in normalized
+// form: the ID, then the classes, then the other attributes in their order
+func headingAttributes(heading *parser.Heading) string {
+	var parts []string
+	if heading.ID != "" {
+		parts = append(parts, "#"+heading.ID)
+	}
+	for _, class := range heading.Classes {
+		parts = append(parts, "."+class)
+	}
+	for _, attribute := range heading.Attributes {
+		parts = append(parts, attribute.Key+"="+attributeValue(attribute.Value))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
 
-	// Apply line width wrapping only if no markdown links are present
-	if r.config.LineWidth > 0 && !r.containsMarkdownLinks(content) {
-		content = r.wrapText(content, r.config.LineWidth)
+// attributeValue writes an attribute value bare when it is a single word or
+// number, and quoted otherwise
+func attributeValue(value string) string {
+	if bareAttributeValue.MatchString(value) {
+		return value
 	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
 
-	r.output.WriteString(content)
+// renderParagraph renders a paragraph node, wrapped at the configured line width
+func (r *MarkdownRenderer) renderParagraph(para *parser.Paragraph, _ int) error {
+	r.output.WriteString(r.wrapInlines(para.Inlines, r.config.LineWidth))
 	r.output.WriteString("\n\n")
 
 	return nil
 }
 
-// containsMarkdownLinks checks if text contains markdown links
-func (r *MarkdownRenderer) containsMarkdownLinks(text string) bool {
-	linkPattern := `\[[^\]]*\]\([^)]*\)`
-	matched, _ := regexp.MatchString(linkPattern, text)
-	// Debug: uncomment to see what's happening
-	// fmt.Printf("DEBUG: Text: %q, Contains links: %v\n", text, matched)
-	return matched
-}
-
-// fixBrokenLinks repairs markdown links that have been broken across lines
-func (r *MarkdownRenderer) fixBrokenLinks(text string) string {
-	const (
-		// Expected parts count for broken link pattern: [text1]\n[text2](url)
-		brokenLinkPartsCount = 4
-		// Expected parts count for multi-break pattern: [text...](url)
-		multiBreakPartsCount = 3
-		// Indices for regex match parts
-		linkTextPart1Index = 1
-		linkTextPart2Index = 2
-		urlPartIndex       = 3
-		linkTextIndex      = 1
-		urlIndex           = 2
-	)
-
-	// Pattern to match broken links: [text\nmore text](url)
-	brokenLinkPattern := `\[([^\]]*)\n([^\]]*)\]\(([^)]*)\)`
-	re := regexp.MustCompile(brokenLinkPattern)
-
-	// Replace broken links with fixed ones
-	fixed := re.ReplaceAllStringFunc(text, func(match string) string {
-		// Extract parts
-		parts := re.FindStringSubmatch(match)
-		if len(parts) == brokenLinkPartsCount {
-			linkText := parts[linkTextPart1Index] + " " + parts[linkTextPart2Index] // Join with space
-			url := parts[urlPartIndex]
-			return "[" + linkText + "](" + url + ")"
-		}
-		return match
-	})
-
-	// Also handle cases where the link text itself has multiple line breaks
-	multiBreakPattern := `\[([^\]]*(?:\n[^\]]*)*)\]\(([^)]*)\)`
-	re2 := regexp.MustCompile(multiBreakPattern)
-
-	fixed = re2.ReplaceAllStringFunc(fixed, func(match string) string {
-		parts := re2.FindStringSubmatch(match)
-		if len(parts) == multiBreakPartsCount {
-			linkText := strings.ReplaceAll(parts[linkTextIndex], "\n", " ")
-			url := parts[urlIndex]
-			return "[" + linkText + "](" + url + ")"
+// renderList renders a list node. Items of loose lists are separated by
+// blank lines.
+func (r *MarkdownRenderer) renderList(list *parser.List, _ int) error {
+	tight := list.Tight && canRenderTight(list)
+	for i, item := range list.Items {
+		if i > 0 && !tight {
+			r.output.WriteString("\n")
 		}
-		return match
-	})
-
-	return fixed
-}
-
-// renderList renders a list node
-func (r *MarkdownRenderer) renderList(list *parser.List, depth int) error {
-	for _, item := range list.Items {
-		if err := r.renderListItem(item, depth+1); err != nil {
+		if err := r.renderListItem(item, tight); err != nil {
 			return err
 		}
 	}
@@ -215,41 +337,128 @@ func (r *MarkdownRenderer) renderList(list *parser.List, depth int) error {
 	return nil
 }
 
-// renderListItem renders a list item node
-func (r *MarkdownRenderer) renderListItem(item *parser.ListItem, depth int) error {
-	// Use proper indentation for nested lists only (depth > 1)
-	indent := ""
-	if depth > 1 {
-		indent = strings.Repeat("  ", depth-1)
+// canRenderTight reports whether the blocks of every list item can follow
+// each other without a blank line. Most blocks cannot directly follow a
+// paragraph without being merged into it, so such items keep a list loose.
+// Any block can follow a heading, which always ends on its own line.
+func canRenderTight(list *parser.List) bool {
+	for _, item := range list.Items {
+		for i, child := range item.Children {
+			if i == 0 && len(item.Inlines) == 0 && item.Task == parser.TaskNone {
+				continue
+			}
+			if i > 0 && item.Children[i-1].Type() == parser.NodeHeading {
+				continue
+			}
+			switch c := child.(type) {
+			case *parser.List, *parser.Blockquote, *parser.MathBlock:
+			case *parser.FootnoteDefinition:
+				// rendered at the end of the document
+			case *parser.CodeBlock:
+				if !c.Fenced {
+					return false
+				}
+			case *parser.Admonition:
+				if c.Syntax != parser.AdmonitionSyntaxGitHub {
+					return false
+				}
+			default:
+				return false
+			}
+		}
 	}
+	return true
+}
 
-	// Determine marker
+// renderListItem renders a list item: its text after the marker, followed by
+// its child blocks. Every further line is indented to the item's content
+// column so that it stays part of the item.
+func (r *MarkdownRenderer) renderListItem(item *parser.ListItem, tight bool) error {
 	marker := item.Marker
 	if marker == "" {
 		marker = r.config.List.BulletStyle
 	}
+	indent := strings.Repeat(" ", utf8.RuneCountInString(marker)+1)
 
-	r.output.WriteString(indent)
-	r.output.WriteString(marker)
-	r.output.WriteString(" ")
-	r.output.WriteString(item.Text)
+	separator := "\n\n"
+	if tight {
+		separator = "\n"
+	}
 
-	// Render nested elements
-	if len(item.Children) > 0 {
-		r.output.WriteString("\n")
-		for _, child := range item.Children {
-			if err := r.renderNode(child, depth); err != nil {
-				return err
-			}
+	var blocks []string
+	text := sentinels.Replace(escapeContinuationLines(r.renderInlines(item.Inlines, true)))
+	if checkBox := taskCheckBox(item.Task); checkBox != "" {
+		text = strings.TrimRight(checkBox+" "+text, " ")
+	} else if r.config.Markdown.HasExtension(config.ExtensionTaskLists) {
+		text = escapeTaskCheckBox(text)
+	}
+	if text != "" {
+		blocks = append(blocks, text)
+	}
+	for i, child := range item.Children {
+		if thematicBreak, ok := child.(*parser.ThematicBreak); ok && len(blocks) == 0 {
+			child = markerLineBreak(marker, thematicBreak)
 		}
-	} else {
-		// Add newline after list item text if no nested elements
+		block, err := r.renderNested([]parser.Node{child}, len(indent))
+		if err != nil {
+			return err
+		}
+		if i > 0 && endsList(item.Children[i-1], child) {
+			blocks = append(blocks, ListEnd)
+		}
+		if block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	content := strings.Join(blocks, separator)
+	if content == "" {
+		r.output.WriteString(marker)
 		r.output.WriteString("\n")
+		return nil
 	}
 
+	firstLine, rest, _ := strings.Cut(content, "\n")
+	r.output.WriteString(marker + " " + firstLine)
+	r.output.WriteString("\n")
+	if rest != "" {
+		r.writeVerbatim(prefixLines(rest, indent, ""))
+		r.output.WriteString("\n")
+	}
 	return nil
 }
 
+// markerLineBreak returns the thematic break to write on the marker line of a
+// list item. A break made of the bullet character would be read as one longer
+// thematic break instead of a list item, so it is written with another one.
+func markerLineBreak(marker string, thematicBreak *parser.ThematicBreak) *parser.ThematicBreak {
+	breakMarker := thematicBreak.Marker
+	if breakMarker == "" {
+		breakMarker = DefaultThematicBreak
+	}
+	if !strings.Contains(breakMarker, marker) {
+		return thematicBreak
+	}
+
+	other := DashBreakCharacter
+	if marker == DashBreakCharacter {
+		other = AsteriskBreakCharacter
+	}
+	return &parser.ThematicBreak{Marker: strings.ReplaceAll(breakMarker, marker, other)}
+}
+
+// taskCheckBox returns the checkbox written for a task list item state
+func taskCheckBox(state parser.TaskState) string {
+	switch state {
+	case parser.TaskUnchecked:
+		return UncheckedTaskBox
+	case parser.TaskChecked:
+		return CheckedTaskBox
+	default:
+		return ""
+	}
+}
+
 // renderCodeBlock renders a code block node
 func (r *MarkdownRenderer) renderCodeBlock(code *parser.CodeBlock, _ int) error {
 	if code.Fenced {
@@ -258,7 +467,7 @@ func (r *MarkdownRenderer) renderCodeBlock(code *parser.CodeBlock, _ int) error
 			r.output.WriteString(code.Language)
 		}
 		r.output.WriteString("\n")
-		r.output.WriteString(code.Content)
+		r.writeVerbatim(code.Content)
 		if !strings.HasSuffix(code.Content, "\n") {
 			r.output.WriteString("\n")
 		}
@@ -266,117 +475,498 @@ func (r *MarkdownRenderer) renderCodeBlock(code *parser.CodeBlock, _ int) error
 		r.output.WriteString("\n\n")
 	} else {
 		// Indented code block
-		lines := strings.Split(code.Content, "\n")
-		for _, line := range lines {
-			r.output.WriteString("    ")
-			r.output.WriteString(line)
+		lines := strings.Split(strings.TrimSuffix(code.Content, "\n"), "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = "    " + line
+			}
+		}
+		r.writeVerbatim(strings.Join(lines, "\n"))
+		r.output.WriteString("\n\n")
+	}
+
+	return nil
+}
+
+// renderBlockquote renders a blockquote node by rendering its children and
+// prefixing every resulting line, so nested quotes accumulate their markers
+func (r *MarkdownRenderer) renderBlockquote(quote *parser.Blockquote, _ int) error {
+	content, err := r.renderNested(quote.Children, len(BlockquotePrefix))
+	if err != nil {
+		return err
+	}
+	if content == "" && len(quote.Children) > 0 {
+		return nil // the quote only held footnote definitions
+	}
+
+	r.writeVerbatim(prefixLines(content, BlockquotePrefix, BlockquoteEmptyPrefix))
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// renderAdmonition renders a GitHub alert as a blockquote that starts with
+// the alert marker, or a MkDocs admonition as a marker line followed by its
+// indented body. Known kinds are written in the casing of their syntax.
+func (r *MarkdownRenderer) renderAdmonition(admonition *parser.Admonition, _ int) error {
+	if admonition.Syntax != parser.AdmonitionSyntaxGitHub {
+		content, err := r.renderNested(admonition.Children, len(AdmonitionIndent))
+		if err != nil {
+			return err
+		}
+
+		r.output.WriteString(mkdocsAdmonitionMarker(admonition))
+		r.output.WriteString("\n")
+		if content != "" {
+			r.writeVerbatim(prefixLines(content, AdmonitionIndent, ""))
 			r.output.WriteString("\n")
 		}
 		r.output.WriteString("\n")
+		return nil
 	}
 
+	content, err := r.renderNested(admonition.Children, len(BlockquotePrefix))
+	if err != nil {
+		return err
+	}
+
+	kind := admonition.Kind
+	if parser.IsKnownAdmonitionKind(parser.AdmonitionSyntaxGitHub, kind) {
+		kind = strings.ToUpper(kind)
+	}
+	marker := "[!" + kind + "]"
+
+	switch {
+	case content == "":
+		content = marker
+	case len(admonition.Children) > 0 && admonition.Children[0].Type() == parser.NodeParagraph:
+		// The first paragraph continues the marker line, so its first word
+		// is escaped like that of any continuation line
+		firstLine, rest, found := strings.Cut(content, "\n")
+		content = escapeContinuationLines(marker + "\n" + firstLine)
+		if found {
+			content += "\n" + rest
+		}
+	default:
+		// Other blocks are separated from the marker, which could otherwise
+		// be read as a paragraph they continue
+		content = marker + "\n\n" + content
+	}
+	r.writeVerbatim(prefixLines(content, BlockquotePrefix, BlockquoteEmptyPrefix))
+	r.output.WriteString("\n\n")
 	return nil
 }
 
-// renderText renders a text node
-func (r *MarkdownRenderer) renderText(text *parser.Text, _ int) error {
-	content := text.Content
+// renderDefinitionList renders each term on its own line and each definition
+// after a colon marker, with its further lines indented to the content
+// column. A blank line comes before loose definitions and before a term that
+// follows a definition, which would otherwise continue it.
+func (r *MarkdownRenderer) renderDefinitionList(list *parser.DefinitionList, _ int) error {
+	for i, child := range list.Children {
+		switch n := child.(type) {
+		case *parser.Term:
+			if i > 0 && list.Children[i-1].Type() == parser.NodeDefinition {
+				r.output.WriteString("\n")
+			}
+			r.output.WriteString(r.renderInlineLine(n.Inlines))
+			r.output.WriteString("\n")
+		case *parser.Definition:
+			if !n.Tight {
+				r.output.WriteString("\n")
+			}
+			if err := r.renderDefinition(n); err != nil {
+				return err
+			}
+		}
+	}
 
-	// Apply whitespace normalization
-	if r.config.Whitespace.TrimTrailingSpaces {
-		lines := strings.Split(content, "\n")
-		for i, line := range lines {
-			lines[i] = strings.TrimRight(line, " \t")
+	r.output.WriteString("\n")
+	return nil
+}
+
+// renderDefinition renders a definition: its first line follows the marker
+// and every further line is indented so that it stays part of the definition
+func (r *MarkdownRenderer) renderDefinition(definition *parser.Definition) error {
+	content, err := r.renderNested(definition.Children, len(DefinitionIndent))
+	if err != nil {
+		return err
+	}
+
+	firstLine, rest, _ := strings.Cut(content, "\n")
+	r.output.WriteString(DefinitionMarker + firstLine)
+	r.output.WriteString("\n")
+	if rest != "" {
+		r.writeVerbatim(prefixLines(rest, DefinitionIndent, ""))
+		r.output.WriteString("\n")
+	}
+	return nil
+}
+
+// mkdocsAdmonitionMarker returns the first line of a MkDocs admonition, with
+// the kind in lower case as MkDocs uses it for CSS classes
+func mkdocsAdmonitionMarker(admonition *parser.Admonition) string {
+	marker := admonition.Marker
+	if marker == "" {
+		marker = parser.MkDocsAdmonitionMarker
+	}
+	marker += " " + strings.Join(strings.Fields(strings.ToLower(admonition.Kind)), " ")
+	if admonition.Title != "" || admonition.HideTitle {
+		marker += ` "` + admonition.Title + `"`
+	}
+	return marker
+}
+
+// renderNested renders child blocks with a separate renderer whose line width
+// is reduced by the width of the prefix the caller will add to each line
+func (r *MarkdownRenderer) renderNested(children []parser.Node, prefixWidth int) (string, error) {
+	nestedConfig := *r.config
+	if nestedConfig.LineWidth > 0 {
+		nestedConfig.LineWidth = max(nestedConfig.LineWidth-prefixWidth, MinNestedLineWidth)
+	}
+
+	nested := &MarkdownRenderer{config: &nestedConfig}
+	if err := nested.renderBlocks(children, 0); err != nil {
+		return "", err
+	}
+
+	content := nested.normalizeBlankLines(nested.output.String(), r.config.Whitespace.MaxBlankLines)
+	return strings.TrimRight(content, "\n"), nil
+}
+
+// prefixLines prepends prefix to every line of text, using emptyPrefix for blank lines
+func prefixLines(text, prefix, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
 		}
-		content = strings.Join(lines, "\n")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderFootnoteDefinition renders a footnote definition. The first line
+// follows the label and every further line is indented so that it stays part
+// of the definition.
+func (r *MarkdownRenderer) renderFootnoteDefinition(definition *parser.FootnoteDefinition, _ int) error {
+	content, err := r.renderNested(definition.Children, len(FootnoteIndent))
+	if err != nil {
+		return err
 	}
 
-	r.output.WriteString(content)
+	label := "[^" + definition.Label + "]:"
+	if content == "" {
+		r.output.WriteString(label)
+		r.output.WriteString("\n\n")
+		return nil
+	}
+
+	firstLine, rest, _ := strings.Cut(content, "\n")
+	r.output.WriteString(label + " " + firstLine)
+	if rest != "" {
+		r.output.WriteString("\n")
+		r.writeVerbatim(prefixLines(rest, FootnoteIndent, ""))
+	}
+	r.output.WriteString("\n\n")
 	return nil
 }
 
-// wrapText wraps text to the specified line width, preserving markdown links
-func (r *MarkdownRenderer) wrapText(text string, width int) string {
-	if width <= 0 {
-		return text
+// renderLinkReferenceDefinition renders a link reference definition on a
+// single line. Consecutive definitions are not separated by blank lines.
+func (r *MarkdownRenderer) renderLinkReferenceDefinition(definition *parser.LinkReferenceDefinition, _ int) error {
+	r.output.WriteString(r.linkReferenceDefinition(definition))
+	r.output.WriteString("\n")
+	r.inDefinitions = true
+	return nil
+}
+
+// endDefinitions closes a run of link reference definitions with a blank line
+func (r *MarkdownRenderer) endDefinitions() {
+	if r.inDefinitions {
+		r.output.WriteString("\n")
+		r.inDefinitions = false
 	}
+}
 
-	// Split text into tokens, preserving markdown links as single units
-	tokens := r.tokenizeWithLinks(text)
-	if len(tokens) == 0 {
-		return text
+// renderTable renders a table node as a pipe table with a normalized delimiter row
+func (r *MarkdownRenderer) renderTable(table *parser.Table, _ int) error {
+	rows := make([][]string, 0, len(table.Rows)+1)
+	if table.Header != nil {
+		rows = append(rows, r.tableRowCells(table.Header))
+	}
+	for _, row := range table.Rows {
+		rows = append(rows, r.tableRowCells(row))
 	}
 
-	var lines []string
-	var currentLine strings.Builder
+	columns := len(table.Alignments)
+	for _, cells := range rows {
+		columns = max(columns, len(cells))
+	}
+	if columns == 0 {
+		return nil
+	}
 
-	for i, token := range tokens {
-		// Check if adding this token would exceed the line width
-		if currentLine.Len() > 0 && currentLine.Len()+1+len(token) > width {
-			// Always start new line when width exceeded (for both links and regular words)
-			lines = append(lines, currentLine.String())
-			currentLine.Reset()
+	aligned := r.config.Table.Style == config.TableStyleAligned
+	widths := make([]int, columns)
+	if aligned {
+		for _, cells := range rows {
+			for i, cell := range cells {
+				widths[i] = max(widths[i], displayWidth(cell))
+			}
 		}
+		for i := range widths {
+			widths[i] = max(widths[i], MinTableDelimiterWidth)
+		}
+	}
 
-		if currentLine.Len() > 0 {
-			currentLine.WriteString(" ")
+	alignments := make([]parser.Alignment, columns)
+	copy(alignments, table.Alignments)
+
+	// Single-column tables always get outer pipes so they still parse as
+	// tables. An empty first cell needs a leading pipe, since the pipe after
+	// it would be read as the leading one, and an empty last cell likewise
+	// needs a trailing pipe. The other lines get the same pipes to stay aligned.
+	pipes := tablePipes{
+		leading:  r.config.Table.LeadingPipe || columns == 1,
+		trailing: r.config.Table.TrailingPipe || columns == 1,
+	}
+	for _, cells := range rows {
+		if len(cells) == 0 || cells[0] == "" {
+			pipes.leading = true
+		}
+		if len(cells) == columns && cells[columns-1] == "" {
+			pipes.trailing = true
 		}
-		currentLine.WriteString(token)
+	}
 
-		// If this is the last token, add the current line
-		if i == len(tokens)-1 {
-			lines = append(lines, currentLine.String())
+	for i, cells := range rows {
+		r.writeTableRow(cells, widths, alignments, pipes)
+		if i == 0 {
+			r.writeTableDelimiter(widths, alignments, aligned, pipes)
 		}
 	}
 
-	return strings.Join(lines, "\n")
+	r.output.WriteString("\n")
+	return nil
 }
 
-// tokenizeWithLinks splits text into words while keeping markdown links intact
-func (r *MarkdownRenderer) tokenizeWithLinks(text string) []string {
-	// Simple regex-based approach to find markdown links
-	linkPattern := `\[[^\]]*\]\([^)]*\)`
-	re := regexp.MustCompile(linkPattern)
+// tableRowCells returns the cell contents of a row with pipes escaped
+func (r *MarkdownRenderer) tableRowCells(row *parser.TableRow) []string {
+	cells := make([]string, len(row.Cells))
+	for i, cell := range row.Cells {
+		cells[i] = escapeTablePipes(strings.TrimSpace(r.renderInlineLine(cell.Inlines)))
+	}
+	return cells
+}
 
-	var tokens []string
-	lastEnd := 0
+// tablePipes tells which outer pipes the lines of a table are written with
+type tablePipes struct {
+	leading  bool
+	trailing bool
+}
 
-	// Find all links and process text between them
-	matches := re.FindAllStringIndex(text, -1)
+// writeTableRow writes a single table row, padding cells to the column widths
+func (r *MarkdownRenderer) writeTableRow(cells []string, widths []int, alignments []parser.Alignment, pipes tablePipes) {
+	padded := make([]string, len(widths))
+	for i := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		padded[i] = padTableCell(cell, widths[i], alignments[i])
+	}
+	r.writeTableLine(padded, pipes)
+}
 
-	for _, match := range matches {
-		start, end := match[0], match[1]
+// writeTableDelimiter writes the delimiter row between the header and the body
+func (r *MarkdownRenderer) writeTableDelimiter(widths []int, alignments []parser.Alignment, aligned bool, pipes tablePipes) {
+	cells := make([]string, len(widths))
+	for i, width := range widths {
+		dashes := MinTableDelimiterWidth
+		if aligned {
+			dashes = width
+		}
 
-		// Add words before the link
-		if start > lastEnd {
-			beforeLink := text[lastEnd:start]
-			words := strings.Fields(beforeLink)
-			tokens = append(tokens, words...)
+		switch alignments[i] {
+		case parser.AlignLeft:
+			cells[i] = ":" + strings.Repeat("-", dashes-1)
+		case parser.AlignCenter:
+			cells[i] = ":" + strings.Repeat("-", dashes-2) + ":"
+		case parser.AlignRight:
+			cells[i] = strings.Repeat("-", dashes-1) + ":"
+		default:
+			cells[i] = strings.Repeat("-", dashes)
 		}
+	}
+	r.writeTableLine(cells, pipes)
+}
+
+// writeTableLine joins cells with pipes, adding the outer pipes
+func (r *MarkdownRenderer) writeTableLine(cells []string, pipes tablePipes) {
+	line := strings.Join(cells, " "+TablePipe+" ")
+	if pipes.leading {
+		line = TablePipe + " " + line
+	}
+	if pipes.trailing {
+		line += " " + TablePipe
+	}
+	r.output.WriteString(strings.TrimRight(line, " "))
+	r.output.WriteString("\n")
+}
 
-		// Add the link as a single token
-		link := text[start:end]
-		tokens = append(tokens, link)
+// padTableCell pads a cell to the given width according to its alignment
+func padTableCell(cell string, width int, alignment parser.Alignment) string {
+	padding := width - displayWidth(cell)
+	if padding <= 0 {
+		return cell
+	}
 
-		lastEnd = end
+	switch alignment {
+	case parser.AlignRight:
+		return strings.Repeat(" ", padding) + cell
+	case parser.AlignCenter:
+		left := padding / centerPaddingDivisor
+		return strings.Repeat(" ", left) + cell + strings.Repeat(" ", padding-left)
+	default:
+		return cell + strings.Repeat(" ", padding)
 	}
+}
 
-	// Add remaining words after the last link
-	if lastEnd < len(text) {
-		afterLinks := text[lastEnd:]
-		words := strings.Fields(afterLinks)
-		tokens = append(tokens, words...)
+// renderThematicBreak renders a thematic break. A dash marker directly below a
+// line of text would turn that text into a setext heading, so the break is
+// always separated from preceding content by a blank line, and it is spaced
+// out when blank lines are going to be removed from the output.
+func (r *MarkdownRenderer) renderThematicBreak(thematicBreak *parser.ThematicBreak, _ int) error {
+	marker := thematicBreak.Marker
+	if marker == "" {
+		marker = DefaultThematicBreak
 	}
 
-	// If no links found, just split into words
-	if len(matches) == 0 {
-		tokens = strings.Fields(text)
+	if strings.HasPrefix(marker, "-") && r.config.Whitespace.MaxBlankLines == 0 {
+		marker = strings.Join(strings.Split(marker, ""), " ")
 	}
 
-	return tokens
+	r.ensureBlankLine()
+	r.output.WriteString(marker)
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// ensureBlankLine terminates the current output with a blank line unless the
+// output is empty or already ends with one
+func (r *MarkdownRenderer) ensureBlankLine() {
+	current := r.output.String()
+	switch {
+	case current == "", strings.HasSuffix(current, "\n\n"):
+		return
+	case strings.HasSuffix(current, "\n"):
+		r.output.WriteString("\n")
+	default:
+		r.output.WriteString("\n\n")
+	}
+}
+
+// renderFrontMatter writes the front matter block between its delimiter lines
+func (r *MarkdownRenderer) renderFrontMatter(frontMatter *parser.FrontMatter) {
+	r.output.WriteString(frontMatter.Delimiter)
+	r.output.WriteString("\n")
+	r.writeVerbatim(frontMatter.Content)
+	if frontMatter.Content != "" && !strings.HasSuffix(frontMatter.Content, "\n") {
+		r.output.WriteString("\n")
+	}
+	r.output.WriteString(frontMatter.Closing)
+	r.output.WriteString("\n\n")
+}
+
+// renderHTMLBlock writes a raw HTML block exactly as it appeared in the source
+func (r *MarkdownRenderer) renderHTMLBlock(html *parser.HTMLBlock, _ int) error {
+	r.writeVerbatim(html.Content)
+	if !strings.HasSuffix(html.Content, "\n") {
+		r.output.WriteString("\n")
+	}
+	r.output.WriteString("\n")
+	return nil
+}
+
+// renderMathBlock writes a display math block exactly as it appeared in the source
+func (r *MarkdownRenderer) renderMathBlock(math *parser.MathBlock, _ int) error {
+	r.writeVerbatim(math.Content)
+	r.output.WriteString("\n\n")
+	return nil
+}
+
+// renderESM writes MDX import and export statements exactly as they appeared in the source
+func (r *MarkdownRenderer) renderESM(esm *parser.ESM, _ int) error {
+	r.writeVerbatim(esm.Content)
+	r.output.WriteString("\n\n")
+	return nil
 }
 
-// normalizeBlankLines limits consecutive blank lines to the configured maximum
+// renderJSX writes the tags of an MDX JSX block exactly as they appeared in
+// the source. The children of an element are rendered between its opening
+// and closing tags, indented as they were.
+func (r *MarkdownRenderer) renderJSX(jsx *parser.JSX, _ int) error {
+	content, err := r.renderNested(jsx.Children, jsx.Indent)
+	if err != nil {
+		return err
+	}
+
+	r.writeVerbatim(jsx.Opening)
+	r.output.WriteString("\n")
+	if content != "" {
+		if !jsx.Tight {
+			r.output.WriteString("\n")
+		}
+		r.writeVerbatim(prefixLines(content, strings.Repeat(" ", jsx.Indent), ""))
+		r.output.WriteString("\n")
+		if !jsx.Tight && jsx.Closing != "" {
+			r.output.WriteString("\n")
+		}
+	}
+	if jsx.Closing != "" {
+		r.writeVerbatim(jsx.Closing)
+		r.output.WriteString("\n")
+	}
+	r.output.WriteString("\n")
+	return nil
+}
+
+// writeVerbatim writes content that must reach the final output unchanged
+func (r *MarkdownRenderer) writeVerbatim(content string) {
+	start := r.output.Len()
+	r.output.WriteString(content)
+	r.verbatim = append(r.verbatim, outputSpan{start: start, end: r.output.Len()})
+}
+
+// isVerbatim reports whether the output byte offset lies inside verbatim content
+func (r *MarkdownRenderer) isVerbatim(offset int) bool {
+	for _, span := range r.verbatim {
+		if offset >= span.start && offset < span.end {
+			return true
+		}
+	}
+	return false
+}
+
+// renderText renders a text node
+func (r *MarkdownRenderer) renderText(text *parser.Text, _ int) error {
+	content := text.Content
+
+	// Apply whitespace normalization
+	if r.config.Whitespace.TrimTrailingSpaces {
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		content = strings.Join(lines, "\n")
+	}
+
+	r.output.WriteString(content)
+	return nil
+}
+
+// normalizeBlankLines limits consecutive blank lines to the configured maximum.
+// Lines inside verbatim output (code, raw HTML) are always kept.
 func (r *MarkdownRenderer) normalizeBlankLines(text string, maxBlankLines int) string {
 	if maxBlankLines < 0 {
 		return text
@@ -385,20 +975,26 @@ func (r *MarkdownRenderer) normalizeBlankLines(text string, maxBlankLines int) s
 	lines := strings.Split(text, "\n")
 	var result []string
 	consecutiveEmpty := 0
+	offset := 0
 
 	for _, line := range lines {
 		isEmpty := strings.TrimSpace(line) == ""
 
-		if isEmpty {
+		switch {
+		case r.isVerbatim(offset):
+			consecutiveEmpty = 0
+			result = append(result, line)
+		case isEmpty:
 			consecutiveEmpty++
 			// Only add empty line if we haven't exceeded the limit
 			if consecutiveEmpty <= maxBlankLines {
 				result = append(result, line)
 			}
-		} else {
+		default:
 			consecutiveEmpty = 0
 			result = append(result, line)
 		}
+		offset += len(line) + 1
 	}
 
 	return strings.Join(result, "\n")
@@ -0,0 +1,476 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gosayram/go-mdfmt/pkg/config"
+	"github.com/Gosayram/go-mdfmt/pkg/parser"
+)
+
+// render parses content and renders it back with cfg
+func render(t *testing.T, cfg *config.Config, content string) string {
+	t.Helper()
+
+	doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	output, err := New().Render(doc, cfg)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	// Only the content is compared, not the blank line after the last block
+	return strings.TrimRight(output, "\n") + "\n"
+}
+
+func TestRender_FootnoteDefinitionsInContainers(t *testing.T) {
+	content := `Text[^a] and[^b] and[^c].
+
+- item
+
+  [^a]: In a list item.
+
+> quote
+>
+> [^b]: In a quote.
+
+> [^c]: Alone in a quote.
+`
+	expected := `Text[^a] and[^b] and[^c].
+
+- item
+
+> quote
+
+[^a]: In a list item.
+
+[^b]: In a quote.
+
+[^c]: Alone in a quote.
+`
+
+	if got := render(t, config.Default(), content); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	// A definition does not make a tight list loose
+	content = "Text[^a].\n\n- one\n  [^a]: Note.\n- two\n"
+	expected = "Text[^a].\n\n- one\n- two\n\n[^a]: Note.\n"
+	if got := render(t, config.Default(), content); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestRender_TableOuterPipes(t *testing.T) {
+	cfg := config.Default()
+	cfg.Table.LeadingPipe = false
+	cfg.Table.TrailingPipe = false
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "without outer pipes",
+			content:  "| a | b |\n|---|---|\n| x | y |\n",
+			expected: "a   | b\n--- | ---\nx   | y\n",
+		},
+		{
+			name:     "empty first cell",
+			content:  "| a | b | c |\n|---|---|---|\n|   | y | z |\n",
+			expected: "| a   | b   | c\n| --- | --- | ---\n|     | y   | z\n",
+		},
+		{
+			name:     "empty last cell",
+			content:  "| a | b |\n|---|--:|\n| x |   |\n",
+			expected: "a   |   b |\n--- | --: |\nx   |     |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, cfg, tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+
+			// The cells must stay in their columns when the output is read back
+			want := tableCells(t, tt.content)
+			if cells := tableCells(t, got); strings.Join(cells, "|") != strings.Join(want, "|") {
+				t.Errorf("Expected cells %q after reparsing, got %q", want, cells)
+			}
+		})
+	}
+}
+
+func TestRender_TableWideCharacters(t *testing.T) {
+	content := "| a | b |\n|---|---|\n| 中文 | é |\n| 😀 | x |\n"
+	expected := `| a    | b   |
+| ---- | --- |
+| 中文 | é   |
+| 😀   | x   |
+`
+
+	if got := render(t, config.Default(), content); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestRender_WideCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"wrapping", "中文字符 中文字符 中文字符 中文字符\n", "中文字符 中文字符\n中文字符 中文字符\n"},
+		{"wrapping emoji", "😀😀😀😀 😀😀😀😀 😀😀\n", "😀😀😀😀 😀😀😀😀\n😀😀\n"},
+		{
+			"wrapping combining marks",
+			"cafe\u0301 cafe\u0301 cafe\u0301 cafe\u0301 cafe\u0301\n",
+			"cafe\u0301 cafe\u0301 cafe\u0301 cafe\u0301\ncafe\u0301\n",
+		},
+		{"setext underline", "中文 😀\n---\n", "中文 😀\n-------\n"},
+		{"setext underline combining marks", "Cafe\u0301\n===\n", "Cafe\u0301\n====\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.LineWidth = 20
+
+			if got := render(t, cfg, tt.content); got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// tableCells parses content and returns the text of every cell of its first table
+func tableCells(t *testing.T, content string) []string {
+	t.Helper()
+
+	doc, err := parser.NewGoldmarkParser().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	table, ok := parser.FindFirstNode(doc, parser.NodeTable).(*parser.Table)
+	if !ok {
+		t.Fatalf("Expected a table in %q", content)
+	}
+
+	var cells []string
+	for _, row := range append([]*parser.TableRow{table.Header}, table.Rows...) {
+		for _, cell := range row.Cells {
+			cells = append(cells, parser.PlainText(cell.Inlines))
+		}
+	}
+	return cells
+}
+
+func TestRender_LinkTitlesAndDestinations(t *testing.T) {
+	tests := []struct {
+		name     string
+		quote    string
+		content  string
+		expected string
+	}{
+		{
+			name:     "double quotes",
+			quote:    config.TitleQuoteDouble,
+			content:  "[a](http://x 'Say \"hi\"')\n",
+			expected: "[a](http://x \"Say \\\"hi\\\"\")\n",
+		},
+		{
+			name:     "single quotes",
+			quote:    config.TitleQuoteSingle,
+			content:  "[a](http://x \"It's\")\n",
+			expected: "[a](http://x 'It\\'s')\n",
+		},
+		{
+			name:     "parentheses",
+			quote:    config.TitleQuoteParentheses,
+			content:  "[a](http://x \"f(x)\")\n",
+			expected: "[a](http://x (f\\(x\\)))\n",
+		},
+		{
+			name:     "destination with spaces",
+			quote:    config.TitleQuoteDouble,
+			content:  "[a](<my file.md> \"T\")\n",
+			expected: "[a](<my file.md> \"T\")\n",
+		},
+		{
+			name:     "unbalanced parenthesis",
+			quote:    config.TitleQuoteDouble,
+			content:  "[a](<x(y>)\n",
+			expected: "[a](<x(y>)\n",
+		},
+		{
+			name:     "balanced parentheses",
+			quote:    config.TitleQuoteDouble,
+			content:  "[a](<x(y)>)\n",
+			expected: "[a](x(y))\n",
+		},
+		{
+			name:     "angle brackets",
+			quote:    config.TitleQuoteDouble,
+			content:  "[a](<\\<x\\>>)\n",
+			expected: "[a](\\<x\\>)\n",
+		},
+		{
+			name:     "empty destination",
+			quote:    config.TitleQuoteSingle,
+			content:  "[a](<> \"T\")\n",
+			expected: "[a](<> 'T')\n",
+		},
+		{
+			name:     "image",
+			quote:    config.TitleQuoteSingle,
+			content:  "![a](<a b.png> \"T\")\n",
+			expected: "![a](<a b.png> 'T')\n",
+		},
+		{
+			name:     "reference definition",
+			quote:    config.TitleQuoteParentheses,
+			content:  "[a]\n\n[a]: <my file.md> \"T\"\n",
+			expected: "[a]\n\n[a]: <my file.md> (T)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Links.TitleQuote = tt.quote
+
+			got := render(t, cfg, tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+
+			// The link must read back with the same destination and title
+			if want, html := toHTML(t, tt.content), toHTML(t, got); html != want {
+				t.Errorf("Expected %q after reparsing, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestRender_ListBoundaries(t *testing.T) {
+	// A heading and a paragraph in one item keep the list tight
+	content := "- # Foo\n- Bar\n  ---\n  baz\n"
+	expected := "- # Foo\n- Bar\n  ---\n  baz\n"
+	got := render(t, config.Default(), content)
+	if got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+	if want, html := toHTML(t, content), toHTML(t, got); html != want {
+		t.Errorf("Expected %q, got %q", want, html)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "in the document",
+			content:  "- a\n- b\n\n<!-- -->\n\n    code\n",
+			expected: "- a\n- b\n\n<!-- -->\n\n    code\n",
+		},
+		{
+			name:     "in a list item",
+			content:  "- x\n  - y\n\n  <!-- -->\n\n      code\n",
+			expected: "- x\n\n  - y\n\n  <!-- -->\n\n      code\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.NewGoldmarkParser().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			// Without the comment, the code block directly follows the list
+			parser.Visit(doc, func(c *parser.Cursor) bool {
+				if c.Node().Type() == parser.NodeHTMLBlock {
+					c.Delete()
+				}
+				return true
+			}, nil)
+
+			output, err := New().Render(doc, config.Default())
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got := strings.TrimRight(output, "\n") + "\n"; got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if want, html := toHTML(t, tt.content), toHTML(t, output); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestRender_ThematicBreakInListItem(t *testing.T) {
+	tests := []struct {
+		bullet   string
+		marker   string
+		expected string
+	}{
+		{"-", "---", "- ***\n"},
+		{"*", "* * *", "* - - -\n"},
+		{"+", "---", "+ ---\n"},
+		{"-", "___", "- ___\n"},
+	}
+
+	// A list whose only item holds a thematic break
+	want := toHTML(t, "+ ---\n")
+	for _, tt := range tests {
+		t.Run(tt.bullet+tt.marker, func(t *testing.T) {
+			doc := &parser.Document{Children: []parser.Node{&parser.List{
+				Marker: tt.bullet,
+				Tight:  true,
+				Items: []*parser.ListItem{{
+					Marker:   tt.bullet,
+					Children: []parser.Node{&parser.ThematicBreak{Marker: tt.marker}},
+				}},
+			}}}
+
+			output, err := New().Render(doc, config.Default())
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got := strings.TrimRight(output, "\n") + "\n"; got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if html := toHTML(t, output); html != want {
+				t.Errorf("Expected %q, got %q", want, html)
+			}
+		})
+	}
+}
+
+func TestRender_ThematicBreakAfterParagraph(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBlankLines int
+		expected      string
+	}{
+		{"blank line", config.DefaultMaxBlankLines, "Text\n\n---\n"},
+		{"no blank lines", 0, "Text\n- - -\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Whitespace.MaxBlankLines = tt.maxBlankLines
+
+			// The break must never become the underline of a setext heading
+			doc := &parser.Document{Children: []parser.Node{
+				&parser.Paragraph{Inlines: []parser.Node{&parser.Text{Content: "Text"}}},
+				&parser.ThematicBreak{Marker: "---"},
+			}}
+			output, err := New().Render(doc, cfg)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got := strings.TrimRight(output, "\n") + "\n"; got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+			if html := toHTML(t, output); html != "<p>Text</p>\n<hr>\n" {
+				t.Errorf("Expected a paragraph and a thematic break, got %q", html)
+			}
+		})
+	}
+}
+
+func TestRender_MDX(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "opening tag with a blank line",
+			content:  "<Foo\n  bar=\"1\"\n\n  baz={2}\n>\nChild text\n</Foo>\n",
+			expected: "<Foo\n  bar=\"1\"\n\n  baz={2}\n>\nChild text\n</Foo>\n",
+		},
+		{
+			name:     "unfinished tag",
+			content:  "<Foo\n  bar=\"1\"\n\nNot a tag.\n",
+			expected: "<Foo\n  bar=\"1\"\n\nNot a tag.\n",
+		},
+		{
+			name:     "statements and self-closing tag",
+			content:  "import X from './x'\nexport const y = 1\n\n<X   a={y} />\n",
+			expected: "import X from './x'\nexport const y = 1\n\n<X   a={y} />\n",
+		},
+		{
+			name:     "indented children",
+			content:  "<Card title=\"A\">\n  Some *child*\n  text\n</Card>\n",
+			expected: "<Card title=\"A\">\n  Some *child* text\n</Card>\n",
+		},
+		{
+			name:     "nested elements",
+			content:  "<Tabs>\n\n<Tab>\n\n- a\n- b\n\n</Tab>\n\n</Tabs>\n",
+			expected: "<Tabs>\n\n<Tab>\n\n- a\n- b\n\n</Tab>\n\n</Tabs>\n",
+		},
+		{
+			name:     "inline tags and expressions",
+			content:  "Text with <Badge  x=\"1\" /> and {1 + 1}.\n",
+			expected: "Text with <Badge  x=\"1\" /> and {1 + 1}.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			doc, err := parser.NewGoldmarkParserFromConfig(cfg, parser.WithMDX()).Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			output, err := New().Render(doc, cfg)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got := strings.TrimRight(output, "\n") + "\n"; got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRender_AlertMarkerInBlockquote(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		alert    bool
+	}{
+		{"alert", "> [!WARNING]\n> Customized title goes on\n", "> [!WARNING]\n> Customized title\n> goes on\n", true},
+		{"text after the marker", "> [!WARNING] Customized title\n", "> [!WARNING] Customized\n> title\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.LineWidth = 12
+
+			got := render(t, cfg, tt.content)
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, got)
+			}
+
+			// Wrapping must not leave a marker alone on the first line
+			doc, err := parser.NewGoldmarkParserFromConfig(cfg).Parse([]byte(got))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if alert := doc.Children[0].Type() == parser.NodeAdmonition; alert != tt.alert {
+				t.Errorf("Expected an alert: %t, got %v", tt.alert, doc.Children[0])
+			}
+		})
+	}
+}
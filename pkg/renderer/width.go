@@ -0,0 +1,43 @@
+package renderer
+
+import "unicode"
+
+// wideCharacters are the East Asian wide and fullwidth characters, which
+// take up two columns in a monospaced font
+var wideCharacters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1}, // Hangul Jamo initial consonants
+		{Lo: 0x2329, Hi: 0x232a, Stride: 1}, // angle brackets
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1}, // CJK radicals, symbols and punctuation
+		{Lo: 0x3041, Hi: 0xa4cf, Stride: 1}, // Kana, CJK ideographs and Yi
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1}, // Hangul syllables
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1}, // CJK compatibility ideographs
+		{Lo: 0xfe10, Hi: 0xfe19, Stride: 1}, // vertical forms
+		{Lo: 0xfe30, Hi: 0xfe6f, Stride: 1}, // CJK compatibility forms
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1}, // fullwidth forms
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1}, // fullwidth signs
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1}, // pictographs and emoticons
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1}, // supplemental pictographs
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1}, // CJK ideographs extension B and later
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1}, // CJK ideographs extension G and later
+	},
+}
+
+// displayWidth returns the number of columns s takes up in a monospaced
+// font: wide characters count twice, and combining marks and format
+// characters such as zero width joiners not at all
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case unicode.Is(wideCharacters, r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}